@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/classify"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/evolution"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/extractor"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+)
+
+func main() {
+	var (
+		repoRoot     = flag.String("repo", ".", "Path to repo root (a git checkout; may be a subdirectory)")
+		rev          = flag.String("rev", "HEAD", "Revision or range to walk, newest first (e.g. HEAD, v1.2.0..main)")
+		maxCommits   = flag.Int("max-commits", 0, "Stop after this many commits touching .go files (0 = all)")
+		changesCSV   = flag.String("changes", evolution.Modified, "Comma-separated change kinds to emit: modified | added | deleted")
+		diffContext  = flag.Int("diff-context", 3, "Context lines around each diff hunk")
+		minFuncLines = flag.Int("min-func-lines", 3, "Skip functions shorter than this many lines (on both sides)")
+		maxFuncLines = flag.Int("max-func-lines", 400, "Hard cap on function lines (after trimming)")
+		closures     = flag.Bool("include-closures", false, "Also pair function literals (symbols like Run$1)")
+		commentMode  = flag.String("comments", "strip", "Comments inside code: strip | keep | list")
+		filePolicy   = flag.String("file-policy", "", "Per file class policy overrides, as scanrepo (default generated=exclude)")
+		excludeCSV   = flag.String("exclude", "(^|/)(vendor|third_party|\\.git|build|dist)/", "Comma-separated regex to exclude paths")
+		outPath      = flag.String("out", "", "Path to JSONL output file (optional, defaults to stdout)")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	var changes []string
+	for _, c := range strings.Split(*changesCSV, ",") {
+		switch c = strings.TrimSpace(c); c {
+		case evolution.Modified, evolution.Added, evolution.Deleted:
+			changes = append(changes, c)
+		case "":
+		default:
+			log.Fatalf("flags: unknown change kind %q (want modified|added|deleted)", c)
+		}
+	}
+	cmode, err := extractor.ParseCommentMode(*commentMode)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	policies, err := classify.ParsePolicies(*filePolicy)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+
+	ex := extractor.NewASTExtractor(*minFuncLines, *maxFuncLines).
+		WithTypes(false).
+		WithClosures(*closures).
+		WithComments(cmode).
+		WithFilePolicies(policies).
+		WithWorkers(1)
+	m := evolution.New(*repoRoot, ex).
+		WithExclude(*excludeCSV).
+		WithChanges(changes...).
+		WithContext(*diffContext)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	je := stream.NewJSONLEmitter[evolution.Record](*outPath, nil, true)
+	if err := m.Run(ctx, *rev, *maxCommits, je.Emit); err != nil {
+		log.Fatalf("evolution: %v", err)
+	}
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"flag"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/filter"
 	ft "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding"
 	ft_strategy "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding/strategies"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
 
@@ -15,6 +17,14 @@ var (
 	outPath       = flag.String("out", "", "Output JSONL for fine-tuning")
 	useCallgraph  = flag.Bool("use-callgraph", false, "Generate questions for callgraph functions instead of all functions")
 	useContextref = flag.Bool("use-contextref", false, "Generate questions for context-referenced functions instead of all functions")
+	useDoc        = flag.Bool("use-doc", false, "Generate \"what does X do?\" questions answered by the record's doc comment")
+	useErrors     = flag.Bool("use-errors", false, "Generate \"what errors can X return?\" questions answered from the errors aspect")
+	useConc       = flag.Bool("use-concurrency", false, "Generate questions on goroutines, channels, locks and context handling from the concurrency aspect")
+	useImpls      = flag.Bool("use-implementers", false, "Generate \"which types implement I?\" questions for interface records with implementers")
+	tokenizerPath = flag.String("tokenizer", "", "BPE tokenizer: tokenizer.json, merges.txt or a directory holding one; adds token counts")
+	maxTokens     = flag.Int("max-record-tokens", 0, "Token budget per fine-tune record; optional context is shed first, then the record is dropped (needs -tokenizer)")
+	filterExpr    = flag.String("filter", "", "Only turn records matching this filter expression into Q/A, e.g. 'exported && score > 0.6'")
+	orderBy       = flag.String("order-by", "", "Emit Q/A in record order by these fields for curriculum training, e.g. 'cognitive,lines' (simplest first) or '-cyclomatic'; reads all records first")
 )
 
 func main() {
@@ -23,6 +33,11 @@ func main() {
 		panic("usage: -in scan.jsonl -out finetune.jsonl [flags]")
 	}
 
+	keep, err := filter.Parse(*filterExpr)
+	utils.MustNotErr(err)
+	order, err := filter.ParseOrder(*orderBy)
+	utils.MustNotErr(err)
+
 	jr, err := stream.NewJSONLReader[model.Record](*inPath, nil)
 	utils.MustNotErr(err)
 	je := stream.NewJSONLEmitter[*ft.FineTuneRecord](*outPath, nil, true)
@@ -34,8 +49,39 @@ func main() {
 	if *useContextref {
 		reg.Register(ft_strategy.NewContextRefsStrategy())
 	}
+	if *useDoc {
+		reg.Register(ft_strategy.NewDocStrategy())
+	}
+	if *useErrors {
+		reg.Register(ft_strategy.NewErrorsStrategy())
+	}
+	if *useConc {
+		reg.Register(ft_strategy.NewConcurrencyStrategy())
+	}
+	if *useImpls {
+		reg.Register(ft_strategy.NewImplementersStrategy())
+	}
 
 	gen := ft.NewGenerator(reg)
+	if *tokenizerPath != "" {
+		tok, err := tokenizer.Load(*tokenizerPath)
+		utils.MustNotErr(err)
+		gen.WithTokenBudget(tok, *maxTokens)
+	} else if *maxTokens > 0 {
+		panic("-max-record-tokens needs -tokenizer")
+	}
+
+	if order != nil {
+		recs, err := jr.ReadAll()
+		utils.MustNotErr(err)
+		recs = keep.Keep(recs)
+		order.Sort(recs)
+		for _, rec := range recs {
+			je.Emit(gen.Generate(rec))
+		}
+		jr.Close()
+		return
+	}
 
 	for {
 		rec, ok, err := jr.Next()
@@ -43,6 +89,9 @@ func main() {
 		if !ok {
 			break
 		}
+		if !keep.Match(&rec) {
+			continue
+		}
 
 		ftRecords := gen.Generate(rec)
 		je.Emit(ftRecords)
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/manifest"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+)
+
+// repoScan is one repo of a run: the -repo flags, or a manifest entry.
+type repoScan struct {
+	Root     string
+	Name     string
+	Commit   string
+	Exclude  string   // comma-separated path regexes (-exclude)
+	Excludes []string // further path regexes, one per entry (manifest)
+	Fields   map[string]bool
+	Out      string // "" = the shared (merged) output
+}
+
+type scanFunc func(ctx context.Context, rs repoScan, em stream.Emitter[model.Record]) error
+
+// manifestScans applies the manifest entries to the command-line defaults:
+// excludes add to -exclude, fields replace -fields.
+func manifestScans(m *manifest.Manifest, excludeCSV, fieldsCSV string) []repoScan {
+	out := make([]repoScan, 0, len(m.Repos))
+	for _, r := range m.Repos {
+		rs := repoScan{
+			Root: r.Path, Name: r.Name, Commit: r.Commit,
+			Exclude:  excludeCSV,
+			Excludes: r.Exclude,
+			Fields:   ParseFields(fieldsCSV),
+			Out:      r.OutPath(m.OutDir),
+		}
+		if len(r.Fields) > 0 {
+			rs.Fields = ParseFields(strings.Join(r.Fields, ","))
+		}
+		out = append(out, rs)
+	}
+	return out
+}
+
+type repoResult struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Commit     string `json:"commit,omitempty"`
+	Out        string `json:"out,omitempty"` // "" = merged output
+	Records    int    `json:"records"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type batchSummary struct {
+	Repos        []repoResult `json:"repos"`
+	TotalRecords int          `json:"total_records"`
+	Failed       int          `json:"failed"`
+}
+
+func (s *batchSummary) write(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// runBatch scans the repos in order. A failing repo is recorded in the
+// summary and the batch moves on; only cancellation stops it early. Records
+// of repos without their own output go to mergedOut (stdout if "").
+func runBatch(ctx context.Context, scans []repoScan, mergedOut string, scan scanFunc) (*batchSummary, error) {
+	sum := &batchSummary{Repos: make([]repoResult, 0, len(scans))}
+	merged := stream.NewJSONLEmitter[model.Record](mergedOut, nil, true)
+	for _, rs := range scans {
+		if err := ctx.Err(); err != nil {
+			return sum, err
+		}
+		em := &countingEmitter{Emitter: stream.Emitter[model.Record](merged)}
+		if rs.Out != "" {
+			em.Emitter = stream.NewJSONLEmitter[model.Record](rs.Out, nil, true)
+		}
+
+		start := time.Now()
+		var err error
+		if rs.Out != "" {
+			err = os.MkdirAll(filepath.Dir(rs.Out), 0o755)
+		}
+		if err == nil {
+			err = scan(ctx, rs, em)
+		}
+		res := repoResult{
+			Name: rs.Name, Path: rs.Root, Commit: rs.Commit, Out: rs.Out,
+			Records: em.n, DurationMS: time.Since(start).Milliseconds(),
+		}
+		sum.TotalRecords += em.n
+		if err != nil {
+			res.Error = err.Error()
+			sum.Failed++
+			log.Printf("repo %s: %v", rs.Name, err)
+		} else {
+			log.Printf("repo %s: %d records in %s", rs.Name, em.n, time.Since(start).Round(time.Millisecond))
+		}
+		sum.Repos = append(sum.Repos, res)
+	}
+	return sum, ctx.Err()
+}
+
+// countingEmitter counts the records passed through to Emitter.
+type countingEmitter struct {
+	stream.Emitter[model.Record]
+	n int
+}
+
+func (c *countingEmitter) Emit(records []model.Record) error {
+	for _, r := range records {
+		if err := c.EmitOne(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *countingEmitter) EmitOne(record model.Record) error {
+	if err := c.Emitter.EmitOne(record); err != nil {
+		return err
+	}
+	c.n++
+	return nil
+}
@@ -4,38 +4,72 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"os/signal"
 	"strings"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
+	ncg "github.com/vd09-projects/techlead-llm-go-data-creater/internal/callgraph"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/classify"
 	baseenrichers "github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/callgraph"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/complexity"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/concurrency"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/contextrefs"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/declrefs"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/errorpaths"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/history"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/imports"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/neighbors"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/selection"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers/testlinks"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/extractor"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/filter"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/gitutil"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/manifest"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/pipeline"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
 )
 
 func main() {
 	var (
 		repoRoot       = flag.String("repo", ".", "Path to repo root")
-		commitRef      = flag.String("commit", "", "Commit hash/ref (metadata only)")
-		includePrivate = flag.Bool("include-private", false, "Include unexported/private functions (honored by extractor)")
+		manifestPath   = flag.String("manifest", "", "JSON/YAML manifest of repos to scan in one run (replaces -repo and -commit)")
+		outDir         = flag.String("out-dir", "", "Manifest: write one <name>.jsonl per repo into this directory (overrides out_dir)")
+		summaryPath    = flag.String("summary", "", "Manifest: write per-repo record counts and failures as JSON here (overrides summary)")
+		commitRef      = flag.String("commit", "", "Commit hash/ref to scan (checked out per -checkout) and label records with")
+		checkoutFlag   = flag.String("checkout", "worktree", "How -commit is materialized: worktree (temp git worktree) | archive (git archive into a temp dir) | none (scan the working tree, commit is metadata only)")
+		sinceRev       = flag.String("since", "", "Incremental: rescan only .go files changed since this git rev and merge into the previous scan")
+		streamOut      = flag.Bool("stream", false, "Emit records directory by directory as they are enriched (one directory loaded at a time; records grouped by directory)")
+		prevPath       = flag.String("prev", "", "Incremental: previous scan JSONL to merge into (default: -out, rewritten in place)")
+		includePrivate = flag.Bool("include-private", false, "Include unexported functions, methods and types (default: same as -filter exported)")
+		filterExpr     = flag.String("filter", "", "Record filter expression, e.g. 'lines >= 5 && !test && path =~ \"^internal/\"'")
 		maxFuncLines   = flag.Int("max-func-lines", 120, "Hard cap on function lines (after trimming)")
 		minFuncLines   = flag.Int("min-func-lines", 3, "Skip functions shorter than this many lines")
+		includeTypes   = flag.Bool("include-types", true, "Emit type declarations (struct, interface, named, alias) as records")
+		includeTests   = flag.Bool("include-tests", true, "Load _test.go files: emit their functions (test_file) and link functions to tests")
+		includeClosure = flag.Bool("include-closures", false, "Emit function literals as records linked to their enclosing function")
+		dropInvalid    = flag.Bool("drop-invalid", false, "Drop records whose trimmed code does not parse (default: keep and flag invalid_code)")
+		trimMode       = flag.String("trim-mode", "head", "Over-long functions: head (keep first lines) | structural (elide inner blocks)")
+		commentMode    = flag.String("comments", "strip", "Comments inside code: strip | keep | list (doc comments always go to the doc field)")
 
 		ctxBefore = flag.Int("context-before", 0, "Neighbor lines before function start (<=30)")
 		ctxAfter  = flag.Int("context-after", 0, "Neighbor lines after function end (<=30)")
 
+		filePolicy = flag.String("file-policy", "", "Per file class policy overrides, e.g. generated=flag,mock=exclude,test=include (classes: generated|mock|protobuf|vendor|example|test; default generated=exclude)")
 		excludeCSV = flag.String("exclude", "(^|/)(vendor|third_party|\\.git|build|dist)/", "Comma-separated regex to exclude paths")
 
-		fieldsCSV = flag.String("fields", "repo,commit,lang,path,symbol,signature,start_line,end_line,code,neighbors,selection,call_graph,context_refs", "Comma-separated output fields")
+		fieldsCSV = flag.String("fields", "repo,commit,lang,kind,path,symbol,signature,start_line,end_line,code,doc,neighbors,selection,call_graph,context_refs,tests,complexity,imports,errors,concurrency", "Comma-separated output fields (opt-in: history, decl_refs)")
 
-		debug   = flag.Bool("debug", false, "Verbose logging")
-		outPath = flag.String("out", "", "Path to JSONL output file (optional, defaults to stdout)")
+		debug    = flag.Bool("debug", false, "Verbose logging")
+		parallel = flag.Int("parallel", 0, "Files extracted / functions enriched concurrently (0 = one per CPU, 1 = sequential)")
+		timeout  = flag.Duration("timeout", 0, "Abort the scan after this long (e.g. 10m; 0 = no limit)")
+		outPath  = flag.String("out", "", "Path to JSONL output file (optional, defaults to stdout; with -manifest, the merged output)")
+
+		historyChurn = flag.Bool("history-churn", true, "history: count commits per function with git log -L (one git run per function; slow on long histories)")
 
 		maxCallers = flag.Int("max-callers", 10, "Max callers included")
 		maxCallees = flag.Int("max-callees", 10, "Max callees included")
@@ -43,9 +77,29 @@ func main() {
 		// NEW: context_refs specific
 		ctxMaxRefs  = flag.Int("context-refs-max", 2, "Max context refs per record (<=2)")
 		ctxMaxLines = flag.Int("context-refs-max-lines", 30, "Max lines per snippet (<=30)")
+
+		// errors specific
+		errorsDepth = flag.Int("errors-depth", 2, "Callee levels searched for returned errors and panics")
+
+		// decl_refs specific
+		declMaxRefs   = flag.Int("decl-refs-max", 8, "Max referenced package-level declarations per record")
+		declMaxLines  = flag.Int("decl-refs-max-lines", 15, "Max lines per declaration snippet (<=60)")
+		declMaxTokens = flag.Int("decl-refs-max-tokens", 0, "Max tokens per declaration snippet")
+
+		// token budgets (need -tokenizer); 0 = no budget
+		tokenizerPath = flag.String("tokenizer", "", "BPE tokenizer: tokenizer.json, merges.txt or a directory holding one; adds token counts")
+		maxFuncTokens = flag.Int("max-func-tokens", 0, "Cap on code tokens per record (trims like -max-func-lines)")
+		nbMaxTokens   = flag.Int("neighbors-max-tokens", 0, "Max tokens per neighbor snippet")
+		ctxMaxTokens  = flag.Int("context-refs-max-tokens", 0, "Max tokens per context ref snippet")
+
+		// build configuration, applied to every package loader
+		buildTags = flag.String("tags", "", "Comma-separated build tags")
+		platforms = flag.String("platforms", "", "Comma-separated GOOS/GOARCH list (e.g. linux/amd64,windows/amd64); several = matrix scan")
+		tagSets   = flag.String("tag-sets", "", "Semicolon-separated tag sets added to -tags (e.g. \";integration;e2e,linux\"); several = matrix scan")
+		modFlag   = flag.String("mod", "", "Module download mode passed to go list: mod | readonly | vendor")
+		offline   = flag.Bool("offline", false, "Never reach the network (GOPROXY=off, GOTOOLCHAIN=local)")
 	)
 	flag.Parse()
-	_ = includePrivate
 
 	log.SetFlags(0)
 	if *debug {
@@ -54,54 +108,246 @@ func main() {
 
 	fields := ParseFields(*fieldsCSV)
 
-	ens := make([]baseenrichers.Enricher, 0, 4)
-	if fields["neighbors"] && (*ctxBefore > 0 || *ctxAfter > 0) {
-		ens = append(ens, neighbors.New(neighbors.Config{
-			Before: *ctxBefore, After: *ctxAfter,
-		}))
-	}
-	if fields["selection"] {
-		ens = append(ens, selection.New(*repoRoot, nil))
-	}
-	if fields["call_graph"] {
-		ens = append(ens, callgraph.New(callgraph.Config{
-			RepoRoot: *repoRoot, MaxCallers: *maxCallers, MaxCallees: *maxCallees,
-		}))
-	}
-	if fields["context_refs"] {
-		// Build semantic index ONCE if context_refs requested
-		idx, err := contextrefs.Load(*repoRoot)
-		if err != nil && *debug {
-			log.Printf("semindex load error: %v", err)
-		} else {
-			ens = append(ens, contextrefs.New(
-				contextrefs.Config{MaxRefs: *ctxMaxRefs, MaxLines: *ctxMaxLines},
-				idx,
-			))
-		}
-	}
-
-	reader := scanner.NewGoPackagesReader(*repoRoot, *excludeCSV, *debug)
-
-	je := stream.NewJSONLEmitter[model.Record](*outPath, nil, true)
-	pl := pipeline.New(
-		reader,
-		extractor.NewASTExtractor(*minFuncLines, *maxFuncLines),
-		ens,
-		je,
-	)
+	keep, err := filter.Parse(*filterExpr)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	if !*includePrivate {
+		keep = filter.And(filter.MustParse("exported"), keep)
+	}
+
+	var tok tokenizer.Counter
+	if *tokenizerPath != "" {
+		bpe, err := tokenizer.Load(*tokenizerPath)
+		if err != nil {
+			log.Fatalf("flags: %v", err)
+		}
+		tok = bpe
+	} else if *maxFuncTokens > 0 || *nbMaxTokens > 0 || *ctxMaxTokens > 0 || *declMaxTokens > 0 {
+		log.Fatalf("flags: token budgets need -tokenizer")
+	}
+
+	base := buildcfg.Config{Tags: buildcfg.ParseTags(*buildTags), Mod: *modFlag, Offline: *offline}
+	if err = base.Validate(); err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	builds, err := buildcfg.Matrix(base, *platforms, *tagSets)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	if len(builds) > 1 && (*sinceRev != "" || *streamOut) {
+		log.Fatalf("flags: -since and -stream take a single build configuration")
+	}
+	if *sinceRev != "" && *streamOut {
+		log.Fatalf("flags: -since merges into the previous scan and cannot -stream")
+	}
+
+	// enrichers load packages themselves, so each repo and build configuration gets its own
+	newEnrichers := func(rs repoScan, b buildcfg.Config) []baseenrichers.Enricher {
+		ens := make([]baseenrichers.Enricher, 0, 5)
+		if rs.Fields["neighbors"] && (*ctxBefore > 0 || *ctxAfter > 0) {
+			ens = append(ens, neighbors.New(neighbors.Config{
+				Before: *ctxBefore, After: *ctxAfter,
+				Tokenizer: tok, MaxTokens: *nbMaxTokens,
+			}).WithWorkers(*parallel))
+		}
+		// history and complexity first: the selection score reads them
+		if rs.Fields["complexity"] {
+			ens = append(ens, complexity.New().WithWorkers(*parallel))
+		}
+		if rs.Fields["concurrency"] {
+			ens = append(ens, concurrency.New().WithWorkers(*parallel))
+		}
+		if rs.Fields["imports"] {
+			ens = append(ens, imports.New().WithWorkers(*parallel))
+		}
+		if rs.Fields["history"] {
+			ens = append(ens, history.New(history.Config{
+				RepoRoot: rs.Root, Churn: *historyChurn, Debug: *debug,
+			}).WithWorkers(*parallel))
+		}
+		if rs.Fields["selection"] {
+			ens = append(ens, selection.New(rs.Root, selection.NewDefaultStrategy(rs.Root, b)).WithWorkers(*parallel))
+		}
+		// one callgraph (SSA build) shared by call_graph and tests
+		cgc := ncg.NewNativeComputer(b)
+		if rs.Fields["call_graph"] {
+			ens = append(ens, callgraph.New(callgraph.Config{
+				RepoRoot: rs.Root, MaxCallers: *maxCallers, MaxCallees: *maxCallees,
+			}).WithComputer(cgc).WithWorkers(*parallel))
+		}
+		if rs.Fields["tests"] {
+			ens = append(ens, testlinks.New(testlinks.Config{RepoRoot: rs.Root}, cgc).WithWorkers(*parallel))
+		}
+		if rs.Fields["errors"] {
+			ens = append(ens, errorpaths.New(errorpaths.Config{
+				RepoRoot: rs.Root, MaxDepth: *errorsDepth,
+			}, cgc).WithWorkers(*parallel))
+		}
+		if rs.Fields["decl_refs"] {
+			idx, err := declrefs.Load(rs.Root, b)
+			if err != nil {
+				log.Printf("decl_refs: %v", err)
+			} else {
+				ens = append(ens, declrefs.New(declrefs.Config{
+					MaxRefs: *declMaxRefs, MaxLines: *declMaxLines,
+					Tokenizer: tok, MaxTokens: *declMaxTokens,
+				}, idx).WithWorkers(*parallel))
+			}
+		}
+		if rs.Fields["context_refs"] {
+			// Build semantic index ONCE if context_refs requested
+			idx, err := contextrefs.Load(rs.Root, b)
+			if err != nil && *debug {
+				log.Printf("semindex load error: %v", err)
+			} else {
+				ens = append(ens, contextrefs.New(
+					contextrefs.Config{
+						MaxRefs: *ctxMaxRefs, MaxLines: *ctxMaxLines,
+						Tokenizer: tok, MaxTokens: *ctxMaxTokens,
+					},
+					idx,
+				).WithWorkers(*parallel))
+			}
+		}
+		return ens
+	}
+
+	cmode, err := extractor.ParseCommentMode(*commentMode)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	checkout, err := gitutil.ParseCheckoutMode(*checkoutFlag)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	tmode, err := extractor.ParseTrimMode(*trimMode)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	policies, err := classify.ParsePolicies(*filePolicy)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	ex := extractor.NewASTExtractor(*minFuncLines, *maxFuncLines).
+		WithTypes(*includeTypes).
+		WithClosures(*includeClosure).
+		WithComments(cmode).
+		WithDropInvalid(*dropInvalid).
+		WithTrimMode(tmode).
+		WithTokenBudget(tok, *maxFuncTokens).
+		WithFilePolicies(policies).
+		WithWorkers(*parallel)
+
+	// scan runs the whole pipeline (or build matrix) over one repo
+	scan := func(ctx context.Context, rs repoScan, em stream.Emitter[model.Record]) error {
+		commit := gitutil.ResolveCommit(rs.Root, "")
+		if rs.Commit != "" {
+			hash, err := gitutil.RevCommit(rs.Root, rs.Commit)
+			if err != nil {
+				return err
+			}
+			commit = hash
+			// scan a temporary copy of the revision; the working tree stays as is
+			dir, cleanup, err := gitutil.Checkout(rs.Root, hash, checkout)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := cleanup(); err != nil {
+					log.Printf("checkout cleanup %s: %v", dir, err)
+				}
+			}()
+			rs.Root = dir
+		}
+
+		newPipeline := func(b buildcfg.Config) *pipeline.Pipeline {
+			reader := scanner.NewGoPackagesReader(rs.Root, rs.Exclude, *debug).
+				WithExcludes(rs.Excludes...).
+				WithTests(*includeTests).
+				WithBuild(b)
+			return pipeline.New(
+				reader,
+				ex,
+				newEnrichers(rs, b),
+				em,
+			)
+		}
+
+		opts := pipeline.Options{
+			RepoRoot:   rs.Root,
+			OutPath:    rs.Out,
+			RepoName:   rs.Name,
+			CommitHash: commit,
+			Lang:       "go",
+			Since:      *sinceRev,
+			PrevPath:   *prevPath,
+			Stream:     *streamOut,
+			Filter:     keep,
+		}
+		if len(builds) > 1 {
+			m := &pipeline.Matrix{Builds: builds, New: newPipeline, Emitter: em}
+			return m.Run(ctx, opts)
+		}
+		return newPipeline(builds[0]).Run(ctx, opts)
+	}
 
-	opts := pipeline.Options{
-		RepoRoot:   *repoRoot,
-		OutPath:    *outPath,
-		RepoName:   gitutil.InferRepoName(*repoRoot),
-		CommitHash: gitutil.ResolveCommit(*repoRoot, *commitRef),
-		Lang:       "go",
+	// Ctrl-C and -timeout cancel the scan; nothing is emitted for a cancelled run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
 
-	if err := pl.Run(context.Background(), opts); err != nil {
+	if *manifestPath == "" {
+		rs := repoScan{
+			Root: *repoRoot, Name: gitutil.InferRepoName(*repoRoot), Commit: *commitRef,
+			Exclude: *excludeCSV, Fields: fields, Out: *outPath,
+		}
+		if err := scan(ctx, rs, stream.NewJSONLEmitter[model.Record](*outPath, nil, true)); err != nil {
+			log.Fatalf("scan error: %v", err)
+		}
+		return
+	}
+
+	m, err := manifest.Load(*manifestPath)
+	if err != nil {
+		log.Fatalf("flags: %v", err)
+	}
+	if *outPath != "" {
+		m.Out = *outPath
+	}
+	if *outDir != "" {
+		m.OutDir = *outDir
+	}
+	if *summaryPath != "" {
+		m.Summary = *summaryPath
+	}
+	scans := manifestScans(m, *excludeCSV, *fieldsCSV)
+	if *sinceRev != "" {
+		if *prevPath != "" {
+			log.Fatalf("flags: -prev names one previous scan; with -manifest each repo merges into its own output")
+		}
+		for _, rs := range scans {
+			if rs.Out == "" {
+				log.Fatalf("flags: -since needs per-repo outputs (out_dir, -out-dir or out); repo %s would be merged", rs.Name)
+			}
+		}
+	}
+	sum, err := runBatch(ctx, scans, m.Out, scan)
+	if m.Summary != "" {
+		if werr := sum.write(m.Summary); werr != nil {
+			log.Printf("summary: %v", werr)
+		}
+	}
+	if err != nil {
 		log.Fatalf("scan error: %v", err)
 	}
+	if sum.Failed > 0 {
+		log.Fatalf("scan error: %d of %d repos failed", sum.Failed, len(sum.Repos))
+	}
 }
 
 func ParseFields(csv string) map[string]bool {
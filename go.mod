@@ -2,9 +2,9 @@ module github.com/vd09-projects/techlead-llm-go-data-creater
 
 go 1.25
 
-require golang.org/x/tools v0.36.0
-
 require (
-	golang.org/x/mod v0.27.0 // indirect
-	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/mod v0.27.0
+	golang.org/x/sync v0.16.0
+	golang.org/x/tools v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
 )
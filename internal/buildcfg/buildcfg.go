@@ -0,0 +1,128 @@
+package buildcfg
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Config is the build configuration every package loader applies (scanner,
+// callgraph, semantic index, selection), so they agree on which files exist.
+type Config struct {
+	GOOS    string   // "" = host
+	GOARCH  string   // "" = host
+	Tags    []string // build tags
+	Mod     string   // -mod: "" (go default) | mod | readonly | vendor
+	Offline bool     // never reach the network: GOPROXY=off, GOTOOLCHAIN=local
+}
+
+// Validate checks the -mod value.
+func (c Config) Validate() error {
+	switch c.Mod {
+	case "", "mod", "readonly", "vendor":
+		return nil
+	}
+	return fmt.Errorf("unknown -mod %q (want mod|readonly|vendor)", c.Mod)
+}
+
+// Env returns base with the platform and network settings appended
+// (later entries win, so they override base).
+func (c Config) Env(base []string) []string {
+	env := append([]string{}, base...)
+	if c.GOOS != "" {
+		env = append(env, "GOOS="+c.GOOS)
+	}
+	if c.GOARCH != "" {
+		env = append(env, "GOARCH="+c.GOARCH)
+	}
+	if c.Offline {
+		env = append(env, "GOPROXY=off", "GOTOOLCHAIN=local")
+	}
+	return env
+}
+
+// Flags returns the go build flags for the tags and -mod.
+func (c Config) Flags() []string {
+	var out []string
+	if len(c.Tags) > 0 {
+		out = append(out, "-tags="+strings.Join(c.Tags, ","))
+	}
+	if c.Mod != "" {
+		out = append(out, "-mod="+c.Mod)
+	}
+	return out
+}
+
+// Apply adds the configuration to a loader config whose Env is already set.
+func (c Config) Apply(pc *packages.Config) *packages.Config {
+	pc.Env = c.Env(pc.Env)
+	pc.BuildFlags = append(pc.BuildFlags, c.Flags()...)
+	return pc
+}
+
+// Label names the configuration in records: "linux/amd64" or
+// "linux/amd64+integration+e2e". An unset platform is the host's.
+func (c Config) Label() string {
+	goos := c.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	goarch := c.GOARCH
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return strings.Join(append([]string{goos + "/" + goarch}, c.Tags...), "+")
+}
+
+// Matrix expands base into one configuration per platform and tag set.
+// platforms is "os/arch,os/arch" (arch optional; "" = base platform only);
+// tagSets is "a,b;c", each set added to base.Tags; an empty set (as in ";a")
+// stands for the base tags alone.
+func Matrix(base Config, platforms, tagSets string) ([]Config, error) {
+	type platform struct{ goos, goarch string }
+	plats := []platform{{base.GOOS, base.GOARCH}}
+	if strings.TrimSpace(platforms) != "" {
+		plats = nil
+		for _, p := range strings.Split(platforms, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			goos, goarch, _ := strings.Cut(p, "/")
+			if goos == "" || strings.Contains(goarch, "/") {
+				return nil, fmt.Errorf("bad platform %q (want os/arch)", p)
+			}
+			plats = append(plats, platform{goos, goarch})
+		}
+	}
+
+	sets := [][]string{nil}
+	if strings.TrimSpace(tagSets) != "" {
+		sets = nil
+		for _, s := range strings.Split(tagSets, ";") {
+			sets = append(sets, ParseTags(s))
+		}
+	}
+
+	var out []Config
+	seen := map[string]bool{}
+	for _, p := range plats {
+		for _, s := range sets {
+			c := base
+			c.GOOS, c.GOARCH = p.goos, p.goarch
+			c.Tags = append(append([]string{}, base.Tags...), s...)
+			if l := c.Label(); !seen[l] {
+				seen[l] = true
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+// ParseTags splits a comma (or space) separated tag list.
+func ParseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
@@ -4,13 +4,16 @@ package callgraph
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 
 	"go/token"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/workspace"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/callgraph/cha"
 	staticcg "golang.org/x/tools/go/callgraph/static"
@@ -44,32 +47,39 @@ type nativeComputer struct {
 	once sync.Once
 	err  error
 
+	build buildcfg.Config
+
 	// immutable after Init
 	repoRoot string
 	absRepo  string
 	prog     *ssa.Program
 	fset     *token.FileSet
 
-	// indexes for fast lookup (populated once)
-	fnByKey        map[fnKey]*ssa.Function
+	// indexes for fast lookup (populated once); a key holds several functions
+	// when a package is also compiled into its test variant "p [p.test]"
+	fnByKey        map[fnKey][]*ssa.Function
 	nodeStaticByFn map[*ssa.Function]*callgraph.Node
 	nodeCHAByFn    map[*ssa.Function]*callgraph.Node
 }
 
-// NewNativeComputer returns a Computer that builds Static and CHA graphs once.
-func NewNativeComputer() Computer { return &nativeComputer{} }
+// NewNativeComputer returns a Computer that builds Static and CHA graphs once,
+// loading packages under the given build configuration.
+func NewNativeComputer(build buildcfg.Config) Computer { return &nativeComputer{build: build} }
 
 func (c *nativeComputer) Init(repoRoot string) error {
 	c.once.Do(func() {
 		c.repoRoot = repoRoot
 		c.absRepo, _ = filepath.Abs(repoRoot)
 
-		if !c.hasGoMod() {
+		ws, err := workspace.Discover(c.absRepo, nil)
+		if err != nil || len(ws.Modules) == 0 {
 			// no module → permissible empty graph
 			return
 		}
+		defer ws.Close()
 
-		pkgs := c.loadPackages()
+		// every module in one load, so cross-module calls are edges too
+		pkgs := c.loadPackages(ws)
 		if len(pkgs) == 0 {
 			return
 		}
@@ -86,7 +96,7 @@ func (c *nativeComputer) Init(repoRoot string) error {
 			chaCG.DeleteSyntheticNodes()
 		}
 
-		c.fnByKey = map[fnKey]*ssa.Function{}
+		c.fnByKey = map[fnKey][]*ssa.Function{}
 		c.nodeStaticByFn = map[*ssa.Function]*callgraph.Node{}
 		c.nodeCHAByFn = map[*ssa.Function]*callgraph.Node{}
 
@@ -100,11 +110,14 @@ func (c *nativeComputer) Init(repoRoot string) error {
 				}
 				store[fn] = node
 				if file := fileFor(c.fset, fn); file != "" {
-					c.fnByKey[fnKey{
+					k := fnKey{
 						Recv: c.recvOf(fn),
 						Name: fn.Name(),
 						File: filepath.ToSlash(file),
-					}] = fn
+					}
+					if !slices.Contains(c.fnByKey[k], fn) {
+						c.fnByKey[k] = append(c.fnByKey[k], fn)
+					}
 				}
 			}
 		}
@@ -117,7 +130,7 @@ func (c *nativeComputer) Init(repoRoot string) error {
 // GetCallees returns up to maxCallees unique callees for the given (fileRel, symbol).
 func (c *nativeComputer) GetCallees(fileRel, symbol string, maxCallees int) ([]model.Edge, error) {
 	nodeS, nodeC := c.getTargetNodes(fileRel, symbol)
-	if nodeS == nil && nodeC == nil {
+	if len(nodeS) == 0 && len(nodeC) == 0 {
 		return nil, nil
 	}
 	out := c.unionOutEdges(nodeS, nodeC, maxCallees)
@@ -128,7 +141,7 @@ func (c *nativeComputer) GetCallees(fileRel, symbol string, maxCallees int) ([]m
 // GetCallers returns up to maxCallers unique callers for the given (fileRel, symbol).
 func (c *nativeComputer) GetCallers(fileRel, symbol string, maxCallers int) ([]model.Edge, error) {
 	nodeS, nodeC := c.getTargetNodes(fileRel, symbol)
-	if nodeS == nil && nodeC == nil {
+	if len(nodeS) == 0 && len(nodeC) == 0 {
 		return nil, nil
 	}
 	out := c.unionInEdges(nodeS, nodeC, maxCallers)
@@ -137,39 +150,37 @@ func (c *nativeComputer) GetCallers(fileRel, symbol string, maxCallers int) ([]m
 }
 
 // getTargetNodes centralizes target resolution and node lookup.
-// Returns the static and CHA nodes for the target (either may be nil).
-func (c *nativeComputer) getTargetNodes(fileRel, symbol string) (nodeS, nodeC *callgraph.Node) {
+// Returns the static and CHA nodes for every copy of the target (either may be empty).
+func (c *nativeComputer) getTargetNodes(fileRel, symbol string) (nodeS, nodeC []*callgraph.Node) {
 	// If Init never ran or repo had no go.mod, we simply return nils.
 	if c.fset == nil {
 		return nil, nil
 	}
-	target := c.resolveTarget(filepath.ToSlash(fileRel), symbol)
-	if target == nil {
-		return nil, nil
+	for _, target := range c.resolveTarget(filepath.ToSlash(fileRel), symbol) {
+		if n := c.nodeStaticByFn[target]; n != nil {
+			nodeS = append(nodeS, n)
+		}
+		if n := c.nodeCHAByFn[target]; n != nil {
+			nodeC = append(nodeC, n)
+		}
 	}
-	return c.nodeStaticByFn[target], c.nodeCHAByFn[target]
+	return nodeS, nodeC
 }
 
 // --------- internal helpers (nativeComputer methods) ---------
 
-func (c *nativeComputer) hasGoMod() bool {
-	_, err := os.Stat(filepath.Join(c.absRepo, "go.mod"))
-	return err == nil
+func (c *nativeComputer) neutralEnv(ws *workspace.Workspace) []string {
+	return append(ws.Env(os.Environ()), "GOFLAGS=")
 }
 
-func (c *nativeComputer) neutralEnv() []string {
-	env := os.Environ()
-	return append(env, "GOWORK=off", "GOFLAGS=")
-}
-
-func (c *nativeComputer) loadPackages() []*packages.Package {
-	cfg := &packages.Config{
+func (c *nativeComputer) loadPackages(ws *workspace.Workspace) []*packages.Package {
+	cfg := c.build.Apply(&packages.Config{
 		Mode:  packages.LoadAllSyntax,
 		Dir:   c.absRepo,
-		Env:   c.neutralEnv(),
-		Tests: false,
-	}
-	pkgs, _ := packages.Load(cfg, "./...")
+		Env:   c.neutralEnv(ws),
+		Tests: true, // test callers link functions to their tests
+	})
+	pkgs, _ := packages.Load(cfg, ws.Patterns()...)
 	_ = packages.PrintErrors(pkgs)
 	return pkgs
 }
@@ -184,39 +195,43 @@ func (c *nativeComputer) buildSSA(pkgs []*packages.Package) (*ssa.Program, *toke
 }
 
 func (c *nativeComputer) recvOf(fn *ssa.Function) string {
-	if fn == nil || fn.Signature == nil || fn.Signature.Recv() == nil {
+	if fn == nil {
+		return ""
+	}
+	fn = outermost(fn)
+	if fn.Signature == nil || fn.Signature.Recv() == nil {
 		return ""
 	}
 	return recvString(fn.Signature.Recv().Type())
 }
 
-func (c *nativeComputer) resolveTarget(targetFileRel, symbol string) *ssa.Function {
+func (c *nativeComputer) resolveTarget(targetFileRel, symbol string) []*ssa.Function {
 	wantRecv, wantName := ParseInputSymbol(symbol)
 
 	// 1) name + recv + file suffix
 	if wantRecv != "" {
-		for k, fn := range c.fnByKey {
+		for k, fns := range c.fnByKey {
 			if k.Name != wantName || !strings.HasSuffix(k.File, targetFileRel) {
 				continue
 			}
 			if k.Recv == wantRecv {
-				return fn
+				return fns
 			}
 		}
 	}
 
 	// 2) name + file suffix (functions only)
 	if wantRecv == "" {
-		for k, fn := range c.fnByKey {
+		for k, fns := range c.fnByKey {
 			if k.Name == wantName && strings.HasSuffix(k.File, targetFileRel) && k.Recv == "" {
-				return fn
+				return fns
 			}
 		}
 	}
 	return nil
 }
 
-func (c *nativeComputer) unionOutEdges(ns, nc *callgraph.Node, capN int) []model.Edge {
+func (c *nativeComputer) unionOutEdges(ns, nc []*callgraph.Node, capN int) []model.Edge {
 	seen := map[string]bool{}
 	var out []model.Edge
 
@@ -243,16 +258,15 @@ func (c *nativeComputer) unionOutEdges(ns, nc *callgraph.Node, capN int) []model
 		}
 	}
 
-	if ns != nil {
-		add(ns.Out)
-	}
-	if len(out) < capN && nc != nil {
-		add(nc.Out)
+	for _, n := range append(ns, nc...) {
+		if len(out) < capN {
+			add(n.Out)
+		}
 	}
 	return out
 }
 
-func (c *nativeComputer) unionInEdges(ns, nc *callgraph.Node, capN int) []model.Edge {
+func (c *nativeComputer) unionInEdges(ns, nc []*callgraph.Node, capN int) []model.Edge {
 	seen := map[string]bool{}
 	var out []model.Edge
 
@@ -279,11 +293,10 @@ func (c *nativeComputer) unionInEdges(ns, nc *callgraph.Node, capN int) []model.
 		}
 	}
 
-	if ns != nil {
-		add(ns.In)
-	}
-	if len(out) < capN && nc != nil {
-		add(nc.In)
+	for _, n := range append(ns, nc...) {
+		if len(out) < capN {
+			add(n.In)
+		}
 	}
 	return out
 }
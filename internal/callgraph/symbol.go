@@ -26,16 +26,26 @@ func ParseInputSymbol(s string) (recv string, name string) {
 	return "", s
 }
 
+// displayName labels anonymous functions ("Foo$1") with the receiver of their
+// enclosing method so they match the extractor's closure symbols.
 func displayName(fn *ssa.Function) string {
 	if fn == nil {
 		return ""
 	}
-	if sig := fn.Signature; sig != nil && sig.Recv() != nil {
+	if sig := outermost(fn).Signature; sig != nil && sig.Recv() != nil {
 		return "(" + recvString(sig.Recv().Type()) + ")." + fn.Name()
 	}
 	return fn.Name()
 }
 
+// outermost walks up from an anonymous function to its enclosing declared function.
+func outermost(fn *ssa.Function) *ssa.Function {
+	for fn.Parent() != nil {
+		fn = fn.Parent()
+	}
+	return fn
+}
+
 func recvString(t types.Type) string {
 	switch tt := t.(type) {
 	case *types.Pointer:
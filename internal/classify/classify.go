@@ -0,0 +1,177 @@
+package classify
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Class labels a file; a file may carry several (a mockgen mock is generated and a mock).
+type Class string
+
+const (
+	Generated Class = "generated" // "// Code generated ... DO NOT EDIT." before the package clause
+	Mock      Class = "mock"      // mockgen output and mock_*.go, *_mock.go, mocks/ files
+	Protobuf  Class = "protobuf"  // protoc stubs: *.pb.go, *.pb.gw.go, protoc-gen-* headers
+	Vendor    Class = "vendor"    // vendored copies under vendor/
+	Example   Class = "example"   // example_*.go, *_example_test.go, example(s)/ directories
+	Test      Class = "test"      // _test.go
+)
+
+// All lists the classes in label order.
+var All = []Class{Generated, Mock, Protobuf, Vendor, Example, Test}
+
+// Policy says what happens to a class of files.
+type Policy string
+
+const (
+	Include Policy = "include" // extract as usual (the label is still carried)
+	Exclude Policy = "exclude" // skip the file
+	Flag    Policy = "flag"    // extract, and mark the records as flagged
+)
+
+// generatedRe is the official rule (go help generate); it must appear before the package clause.
+var generatedRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// File returns the classes of a file from its posix path (relative to the
+// repo root) and source text, in All order; nil means ordinary source.
+func File(rel, src string) []Class {
+	header := headerLines(src)
+	is := map[Class]bool{}
+
+	for _, l := range header {
+		if generatedRe.MatchString(l) {
+			is[Generated] = true
+			if strings.Contains(l, "by MockGen") {
+				is[Mock] = true
+			}
+			if strings.Contains(l, "by protoc-gen-") {
+				is[Protobuf] = true
+			}
+		}
+	}
+
+	base := path.Base(rel)
+	dirs := strings.Split(path.Dir(rel), "/")
+	hasDir := func(names ...string) bool {
+		for _, d := range dirs {
+			for _, n := range names {
+				if d == n {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if strings.HasPrefix(base, "mock_") || strings.HasSuffix(base, "_mock.go") || strings.HasSuffix(base, "_mock_test.go") ||
+		hasDir("mocks") {
+		is[Mock] = true
+	}
+	if strings.HasSuffix(base, ".pb.go") || strings.HasSuffix(base, ".pb.gw.go") {
+		is[Protobuf] = true
+	}
+	if hasDir("vendor") {
+		is[Vendor] = true
+	}
+	if strings.HasPrefix(base, "example_") || strings.HasSuffix(base, "_example_test.go") ||
+		hasDir("example", "examples", "_examples") {
+		is[Example] = true
+	}
+	if strings.HasSuffix(base, "_test.go") {
+		is[Test] = true
+	}
+
+	var out []Class
+	for _, c := range All {
+		if is[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// headerLines returns the lines before the package clause.
+func headerLines(src string) []string {
+	var out []string
+	for _, l := range strings.Split(src, "\n") {
+		if strings.HasPrefix(l, "package ") {
+			break
+		}
+		out = append(out, strings.TrimRight(l, "\r"))
+	}
+	return out
+}
+
+// Policies maps classes to policies; classes without an entry are included.
+type Policies map[Class]Policy
+
+// DefaultPolicies keeps generated code out, as the extractor always did
+// (mockgen mocks and protoc stubs are generated too), and includes the rest.
+func DefaultPolicies() Policies {
+	return Policies{Generated: Exclude}
+}
+
+// Resolve picks the policy for a file with the given classes: exclude wins
+// over flag, flag over include.
+func (p Policies) Resolve(classes []Class) Policy {
+	out := Include
+	for _, c := range classes {
+		switch p[c] {
+		case Exclude:
+			return Exclude
+		case Flag:
+			out = Flag
+		}
+	}
+	return out
+}
+
+// ParsePolicies applies "class=policy,class=policy" overrides to the defaults.
+func ParsePolicies(csv string) (Policies, error) {
+	out := DefaultPolicies()
+	for _, kv := range strings.Split(csv, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		c, pol := Class(strings.TrimSpace(k)), Policy(strings.TrimSpace(v))
+		if !ok || !known(c) {
+			return nil, fmt.Errorf("bad file policy %q (want class=policy, class one of %s)", kv, joinClasses(All))
+		}
+		switch pol {
+		case Include, Exclude, Flag:
+			out[c] = pol
+		default:
+			return nil, fmt.Errorf("bad file policy %q (want include|exclude|flag)", kv)
+		}
+	}
+	return out, nil
+}
+
+// Strings converts classes for records.
+func Strings(cs []Class) []string {
+	if len(cs) == 0 {
+		return nil
+	}
+	out := make([]string, len(cs))
+	for i, c := range cs {
+		out[i] = string(c)
+	}
+	return out
+}
+
+func known(c Class) bool {
+	for _, k := range All {
+		if k == c {
+			return true
+		}
+	}
+	return false
+}
+
+func joinClasses(cs []Class) string {
+	return strings.Join(Strings(cs), "|")
+}
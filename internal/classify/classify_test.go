@@ -0,0 +1,95 @@
+package classify
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	const plain = "package x\n\nfunc F() {}\n"
+	tests := []struct {
+		name string
+		rel  string
+		src  string
+		want []Class
+	}{
+		{"ordinary source", "internal/x/x.go", plain, nil},
+
+		// generated-code header
+		{"generated header", "x.go", "// Code generated by stringer; DO NOT EDIT.\n\npackage x\n", []Class{Generated}},
+		{"generated header after license", "x.go", "// Copyright 2024\n\n// Code generated by go-bindata. DO NOT EDIT.\n// sources:\n\npackage x\n", []Class{Generated}},
+		{"generated header with CRLF", "x.go", "// Code generated by x. DO NOT EDIT.\r\n\r\npackage x\r\n", []Class{Generated}},
+		{"header after the package clause", "x.go", "package x\n\n// Code generated by x. DO NOT EDIT.\n", nil},
+		{"header without the final period", "x.go", "// Code generated by x. DO NOT EDIT\n\npackage x\n", nil},
+		{"header not at line start", "x.go", "/* // Code generated by x. DO NOT EDIT. */\npackage x\n", nil},
+		{"mention in a doc comment", "x.go", "// This file is not Code generated but DO NOT EDIT.\npackage x\n", nil},
+
+		// mocks
+		{"mockgen output", "x/mock.go", "// Code generated by MockGen. DO NOT EDIT.\n// Source: x.go\n\npackage x\n", []Class{Generated, Mock}},
+		{"mock_ prefix", "x/mock_store.go", plain, []Class{Mock}},
+		{"_mock suffix", "x/store_mock.go", plain, []Class{Mock}},
+		{"_mock_test suffix", "x/store_mock_test.go", plain, []Class{Mock, Test}},
+		{"mocks directory", "internal/mocks/store.go", plain, []Class{Mock}},
+		{"mocks is a whole directory name", "internal/nomocks/store.go", plain, nil},
+
+		// protobuf
+		{"protoc stub", "api/v1/api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage v1\n", []Class{Generated, Protobuf}},
+		{"grpc-gateway stub by name", "api/v1/api.pb.gw.go", plain, []Class{Protobuf}},
+
+		// vendor
+		{"vendored file", "vendor/github.com/a/b/b.go", plain, []Class{Vendor}},
+		{"nested vendor", "tools/vendor/a/a.go", plain, []Class{Vendor}},
+		{"vendor as a file name", "internal/vendor.go", plain, nil},
+
+		// examples
+		{"example_ prefix", "x/example_server.go", plain, []Class{Example}},
+		{"_example_test suffix", "x/server_example_test.go", plain, []Class{Example, Test}},
+		{"examples directory", "examples/basic/main.go", plain, []Class{Example}},
+		{"_examples directory", "_examples/basic/main.go", plain, []Class{Example}},
+
+		// tests
+		{"test file", "x/x_test.go", plain, []Class{Test}},
+		{"test in name only", "x/testdata.go", plain, nil},
+		{"vendored generated mock test", "vendor/a/mocks/mock_x_test.go", "// Code generated by MockGen. DO NOT EDIT.\npackage a\n",
+			[]Class{Generated, Mock, Vendor, Test}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := File(tt.rel, tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("File(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	p, err := ParsePolicies("mock=flag, test=exclude,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		classes []Class
+		want    Policy
+	}{
+		{nil, Include},
+		{[]Class{Example}, Include},
+		{[]Class{Generated}, Exclude}, // default
+		{[]Class{Mock}, Flag},
+		{[]Class{Mock, Test}, Exclude},
+		{[]Class{Example, Mock}, Flag},
+	}
+	for _, tt := range tests {
+		if got := p.Resolve(tt.classes); got != tt.want {
+			t.Errorf("Resolve(%v) = %s, want %s", tt.classes, got, tt.want)
+		}
+	}
+
+	if p, err := ParsePolicies("generated=include"); err != nil || p.Resolve([]Class{Generated}) != Include {
+		t.Errorf("generated=include: %v, %v", p, err)
+	}
+	for _, bad := range []string{"mock", "fixture=flag", "mock=skip"} {
+		if _, err := ParsePolicies(bad); err == nil {
+			t.Errorf("ParsePolicies(%q) succeeded, want error", bad)
+		}
+	}
+}
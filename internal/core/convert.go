@@ -12,15 +12,30 @@ func ToRecords(repo *RepoNode, repoName, commitHash, lang string) []model.Record
 	for _, f := range repo.Files {
 		for _, fn := range f.Functions {
 			rec := model.Record{
-				Repo:      repoName,
-				Commit:    commitHash,
-				Lang:      lang,
-				Path:      f.RelPath,
-				Symbol:    symbolOf(fn),
-				Signature: strings.TrimSpace(fn.Signature),
-				StartLine: fn.StartLine,
-				EndLine:   fn.EndLine,
-				Code:      fn.Code,
+				Repo:        repoName,
+				Commit:      commitHash,
+				Lang:        lang,
+				Kind:        kindOf(fn),
+				Module:      f.Module,
+				Path:        f.RelPath,
+				Symbol:      fn.Symbol(),
+				Signature:   strings.TrimSpace(fn.Signature),
+				TypedSig:    fn.TypedSig,
+				TypeRefs:    fn.TypeRefs,
+				StartLine:   fn.StartLine,
+				EndLine:     fn.EndLine,
+				Code:        fn.Code,
+				Tokens:      fn.Tokens,
+				Doc:         fn.Doc,
+				Comments:    fn.Comments,
+				InvalidCode: fn.InvalidCode,
+				TestFile:    fn.IsTestFile,
+				FileClass:   f.Classes,
+				Flagged:     f.Flagged,
+				Elided:      fn.Elided,
+				Parent:      fn.Parent,
+				Captures:    fn.Captures,
+				StartCol:    fn.StartCol,
 			}
 
 			if v, ok := fn.Aspects[AspectNeighbors].([]model.Neighbor); ok {
@@ -35,23 +50,90 @@ func ToRecords(repo *RepoNode, repoName, commitHash, lang string) []model.Record
 			if v, ok := fn.Aspects[AspectCtxRefs].([]*model.ContextRef); ok && len(v) > 0 {
 				rec.ContextRefs = v
 			}
+			if v, ok := fn.Aspects[AspectTests].([]model.TestLink); ok && len(v) > 0 {
+				rec.Tests = v
+			}
+			if v, ok := fn.Aspects[AspectDeclRefs].([]model.DeclRef); ok && len(v) > 0 {
+				rec.DeclRefs = v
+			}
+			if v, ok := fn.Aspects[AspectImports].([]model.Import); ok && len(v) > 0 {
+				rec.Imports = v
+			}
+			if v, ok := fn.Aspects[AspectErrors].(*model.Errors); ok {
+				rec.Errors = v
+			}
+			if v, ok := fn.Aspects[AspectConcurrent].(*model.Concurrency); ok {
+				rec.Concurrency = v
+			}
+			if v, ok := fn.Aspects[AspectHistory].(*model.History); ok {
+				rec.History = v
+			}
+			if v, ok := fn.Aspects[AspectComplexity].(*model.Complexity); ok {
+				rec.Complexity = v
+			}
 			out = append(out, rec)
 		}
+		for _, t := range f.Types {
+			out = append(out, typeRecord(f, t, repoName, commitHash, lang))
+		}
 	}
 
-	// Stable order: path asc, start_line asc
+	SortRecords(out)
+	return out
+}
+
+// SortRecords applies the stable output order: path asc, start_line asc.
+func SortRecords(out []model.Record) {
 	sort.Slice(out, func(i, j int) bool {
 		if out[i].Path == out[j].Path {
 			return out[i].StartLine < out[j].StartLine
 		}
 		return out[i].Path < out[j].Path
 	})
-	return out
 }
 
-func symbolOf(fn *FunctionNode) string {
+func typeRecord(f *FileNode, t *TypeNode, repoName, commitHash, lang string) model.Record {
+	rec := model.Record{
+		Repo:        repoName,
+		Commit:      commitHash,
+		Lang:        lang,
+		Kind:        model.KindType,
+		Module:      f.Module,
+		Path:        f.RelPath,
+		Symbol:      t.Name,
+		Signature:   strings.TrimSpace(t.Signature),
+		StartLine:   t.StartLine,
+		EndLine:     t.EndLine,
+		Code:        t.Code,
+		Tokens:      t.Tokens,
+		Doc:         t.Doc,
+		Comments:    t.Comments,
+		InvalidCode: t.InvalidCode,
+		TestFile:    t.IsTestFile,
+		FileClass:   f.Classes,
+		Flagged:     f.Flagged,
+		Type: &model.TypeDecl{
+			Kind:    t.Kind,
+			Fields:  t.Fields,
+			Embeds:  t.Embeds,
+			Methods: t.Methods,
+		},
+	}
+	if v, ok := t.Aspects[AspectCtxRefs].([]*model.ContextRef); ok && len(v) > 0 {
+		rec.ContextRefs = v
+	}
+	if v, ok := t.Aspects[AspectImplements].([]model.Implementer); ok && len(v) > 0 {
+		rec.Type.Implementers = v
+	}
+	return rec
+}
+
+func kindOf(fn *FunctionNode) string {
+	if fn.Parent != "" {
+		return model.KindClosure
+	}
 	if fn.Recv == "" {
-		return fn.Name
+		return model.KindFunction
 	}
-	return fn.Recv + "." + fn.Name
+	return model.KindMethod
 }
@@ -1,33 +1,125 @@
 package core
 
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
 type AspectKind string
 
 const (
-	AspectNeighbors AspectKind = "neighbors"
-	AspectSelection AspectKind = "selection"
-	AspectCallGraph AspectKind = "call_graph"
-	AspectCtxRefs   AspectKind = "context_refs"
+	AspectNeighbors  AspectKind = "neighbors"
+	AspectSelection  AspectKind = "selection"
+	AspectCallGraph  AspectKind = "call_graph"
+	AspectCtxRefs    AspectKind = "context_refs"
+	AspectTests      AspectKind = "tests"
+	AspectHistory    AspectKind = "history"
+	AspectComplexity AspectKind = "complexity"
+	AspectDeclRefs   AspectKind = "decl_refs"
+	AspectImports    AspectKind = "imports"
+	AspectErrors     AspectKind = "errors"
+	AspectConcurrent AspectKind = "concurrency"
+	AspectImplements AspectKind = "implementers"
 )
 
 type RepoNode struct {
 	Root  string
 	Files []*FileNode
+
+	// Sources, when set, returns the lines of repo files that are not in Files
+	// (streaming mode holds one directory at a time); nil if unreadable.
+	Sources func(rel string) []string
+
+	shared sync.Map // see Shared
+}
+
+// Shared returns the value kept under key, building it on first use. It lets
+// enrichers share per-repo state, such as parsed files, that lives as long as
+// the node.
+func (r *RepoNode) Shared(key any, build func() any) any {
+	if v, ok := r.shared.Load(key); ok {
+		return v
+	}
+	v, _ := r.shared.LoadOrStore(key, build())
+	return v
 }
 
 type FileNode struct {
 	RelPath   string
+	Module    string   // go.mod module path
 	Lines     []string // for neighbors; kept optional but handy
+	Imports   []model.Import
 	Functions []*FunctionNode
+	Types     []*TypeNode
+	Classes   []string // classify labels: generated, mock, protobuf, vendor, example, test
+	Flagged   bool     // a class has the "flag" policy
+
+	// The reader's syntax tree and type information (Info may be nil or
+	// partial); all nil for files known by their text only.
+	Syntax *ast.File
+	Fset   *token.FileSet
+	Info   *types.Info
 }
 
 type FunctionNode struct {
 	Name          string
 	Recv          string
 	Signature     string
+	TypedSig      string   // signature with import-path-qualified types (needs type info)
+	TypeRefs      []string // named types the signature references, "path.Name"
 	StartLine     int
 	EndLine       int
 	TrimmedLength int
 	Code          string
+	Tokens        int             // tokens in Code, when a tokenizer is configured
+	Doc           string          // godoc text of the declaration
+	Comments      []model.Comment // inline comments, populated in list mode
+	InvalidCode   bool            // emitted Code does not parse
+	Elided        []model.Span    // source lines elided by structural trimming
 	IsTestFile    bool
 	Aspects       map[AspectKind]any
+
+	// Closures only: enclosing function symbol, captured variables, column of "func".
+	Parent   string
+	Captures []string
+	StartCol int
+}
+
+// Symbol names the function the way records and callgraph edges do: "F",
+// "(T).M" or "(*T).M".
+func (fn *FunctionNode) Symbol() string {
+	if fn.Recv == "" {
+		return fn.Name
+	}
+	return fn.Recv + "." + fn.Name
+}
+
+// Type kinds (TypeNode.Kind).
+const (
+	TypeStruct    = "struct"
+	TypeInterface = "interface"
+	TypeNamed     = "named"
+	TypeAlias     = "alias"
+)
+
+type TypeNode struct {
+	Name        string
+	Kind        string // struct | interface | named | alias
+	Signature   string // "type T struct", "type ID = string", ...
+	StartLine   int
+	EndLine     int
+	Code        string
+	Tokens      int
+	Doc         string
+	Comments    []model.Comment
+	InvalidCode bool               // emitted Code does not parse
+	Fields      []model.TypeField  // struct fields (incl. embedded)
+	Embeds      []string           // embedded types (struct + interface)
+	Methods     []model.TypeMethod // interface methods, or method set declared in the package
+	IsTestFile  bool
+	Aspects     map[AspectKind]any
 }
@@ -5,8 +5,10 @@ import (
 	"context"
 	"log"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
 	ncg "github.com/vd09-projects/techlead-llm-go-data-creater/internal/callgraph"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
@@ -22,13 +24,14 @@ type Enricher struct {
 	cfg       Config
 	computer  ncg.Computer
 	initError error
+	workers   int
 }
 
 // New wires a native computer by default (can inject a mock in tests).
 func New(cfg Config) *Enricher {
 	return &Enricher{
 		cfg:      cfg,
-		computer: ncg.NewNativeComputer(),
+		computer: ncg.NewNativeComputer(buildcfg.Config{}),
 	}
 }
 
@@ -38,9 +41,15 @@ func (e *Enricher) WithComputer(c ncg.Computer) *Enricher {
 	return e
 }
 
+// WithWorkers sets how many functions are queried concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
 func (e *Enricher) Kind() core.AspectKind { return core.AspectCallGraph }
 
-func (e *Enricher) Enrich(_ context.Context, repo *core.RepoNode) error {
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
 	if repo == nil {
 		return nil
 	}
@@ -50,36 +59,32 @@ func (e *Enricher) Enrich(_ context.Context, repo *core.RepoNode) error {
 		e.initError = err
 	}
 
-	for _, f := range repo.Files {
-		if f == nil || len(f.Functions) == 0 {
-			continue
+	// the computer is read-only after Init, so queries can run concurrently
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		// Build symbol: "Func" or "(Recv).Func"
+		sym := fn.Name
+		if fn.Recv != "" {
+			sym = fn.Recv + "." + fn.Name
 		}
-		for _, fn := range f.Functions {
-			// Build symbol: "Func" or "(Recv).Func"
-			sym := fn.Name
-			if fn.Recv != "" {
-				sym = fn.Recv + "." + fn.Name
-			}
 
-			callgraphResponse := &model.CallGraph{
-				Callees:   nil,
-				Callers:   nil,
-				Precision: "native",
-			}
+		callgraphResponse := &model.CallGraph{
+			Callees:   nil,
+			Callers:   nil,
+			Precision: "native",
+		}
 
-			if callees, err := e.computer.GetCallees(f.RelPath, sym, e.cfg.MaxCallees); err == nil {
-				callgraphResponse.Callees = utils.If(len(callees) > 0, callees).Else(nil)
-			} else {
-				log.Printf("callgraph: failed to get callees for %s in %s: %v", sym, f.RelPath, err)
-			}
+		if callees, err := e.computer.GetCallees(f.RelPath, sym, e.cfg.MaxCallees); err == nil {
+			callgraphResponse.Callees = utils.If(len(callees) > 0, callees).Else(nil)
+		} else {
+			log.Printf("callgraph: failed to get callees for %s in %s: %v", sym, f.RelPath, err)
+		}
 
-			if callers, err := e.computer.GetCallers(f.RelPath, sym, e.cfg.MaxCallers); err == nil {
-				callgraphResponse.Callers = utils.If(len(callers) > 0, callers).Else(nil)
-			} else {
-				log.Printf("callgraph: failed to get callers for %s in %s: %v", sym, f.RelPath, err)
-			}
-			fn.Aspects[core.AspectCallGraph] = callgraphResponse
+		if callers, err := e.computer.GetCallers(f.RelPath, sym, e.cfg.MaxCallers); err == nil {
+			callgraphResponse.Callers = utils.If(len(callers) > 0, callers).Else(nil)
+		} else {
+			log.Printf("callgraph: failed to get callers for %s in %s: %v", sym, f.RelPath, err)
 		}
-	}
-	return nil
+		fn.Aspects[core.AspectCallGraph] = callgraphResponse
+		return nil
+	})
 }
@@ -0,0 +1,38 @@
+package complexity
+
+import (
+	"context"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+)
+
+// Enricher attaches model.Complexity to functions. It measures the full
+// source (FileNode.Lines), not the trimmed code, through the repo's shared
+// enrichers.Syntax.
+type Enricher struct {
+	workers int
+}
+
+func New() *Enricher { return &Enricher{} }
+
+// WithWorkers sets how many functions are measured concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectComplexity }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	syntax := enrichers.SyntaxOf(repo)
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		if node := syntax.FuncNode(f, fn); node != nil {
+			fn.Aspects[core.AspectComplexity] = Measure(node)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,282 @@
+package complexity
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// Measure computes the metrics of a *ast.FuncDecl or *ast.FuncLit.
+func Measure(fn ast.Node) *model.Complexity {
+	var (
+		typ  *ast.FuncType
+		body *ast.BlockStmt
+		self selfRef
+	)
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		typ, body = fn.Type, fn.Body
+		self.name = fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+			self.recv = fn.Recv.List[0].Names[0].Name
+		}
+	case *ast.FuncLit:
+		typ, body = fn.Type, fn.Body
+	default:
+		return nil
+	}
+
+	c := &model.Complexity{
+		Cyclomatic: 1,
+		Params:     fieldCount(typ.Params),
+		Results:    fieldCount(typ.Results),
+	}
+	if body == nil {
+		return c
+	}
+	cog := &cognitive{self: self}
+	cog.block(body, 0)
+	c.Cognitive, c.MaxNesting = cog.score, cog.maxNesting
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			c.Cyclomatic++
+		case *ast.CaseClause:
+			if n.List != nil { // default adds no path
+				c.Cyclomatic++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				c.Cyclomatic++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				c.Cyclomatic++
+			}
+		}
+		if s, ok := n.(ast.Stmt); ok {
+			if _, block := s.(*ast.BlockStmt); !block {
+				c.Statements++
+			}
+		}
+		return true
+	})
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false // returns from the literal
+		case *ast.ReturnStmt:
+			c.Returns++
+		}
+		return true
+	})
+	return c
+}
+
+func fieldCount(fl *ast.FieldList) int {
+	if fl == nil {
+		return 0
+	}
+	n := 0
+	for _, f := range fl.List {
+		n += max(1, len(f.Names))
+	}
+	return n
+}
+
+// selfRef names the measured function, to spot recursive calls.
+type selfRef struct {
+	name string // "" for literals
+	recv string // receiver variable of a method
+}
+
+// cognitive scores control flow per SonarSource's cognitive complexity, as
+// gocognit applies it to Go: +1 for each if / else if / else, switch, select,
+// loop, labeled jump, run of like boolean operators and recursive call, plus
+// the nesting level for structures that nest.
+type cognitive struct {
+	self       selfRef
+	score      int
+	maxNesting int
+}
+
+func (c *cognitive) nested(level int) {
+	c.maxNesting = max(c.maxNesting, level)
+}
+
+func (c *cognitive) block(b *ast.BlockStmt, level int) {
+	if b == nil {
+		return
+	}
+	for _, s := range b.List {
+		c.stmt(s, level)
+	}
+}
+
+func (c *cognitive) stmt(s ast.Stmt, level int) {
+	switch s := s.(type) {
+	case *ast.IfStmt:
+		c.score += 1 + level
+		c.ifChain(s, level)
+	case *ast.ForStmt:
+		c.score += 1 + level
+		c.stmt(s.Init, level)
+		c.expr(s.Cond, level)
+		c.stmt(s.Post, level)
+		c.nested(level + 1)
+		c.block(s.Body, level+1)
+	case *ast.RangeStmt:
+		c.score += 1 + level
+		c.expr(s.X, level)
+		c.nested(level + 1)
+		c.block(s.Body, level+1)
+	case *ast.SwitchStmt:
+		c.score += 1 + level
+		c.stmt(s.Init, level)
+		c.expr(s.Tag, level)
+		c.clauses(s.Body, level+1)
+	case *ast.TypeSwitchStmt:
+		c.score += 1 + level
+		c.stmt(s.Init, level)
+		c.stmt(s.Assign, level)
+		c.clauses(s.Body, level+1)
+	case *ast.SelectStmt:
+		c.score += 1 + level
+		c.clauses(s.Body, level+1)
+	case *ast.BranchStmt:
+		if s.Label != nil && s.Tok != token.FALLTHROUGH {
+			c.score++
+		}
+	case *ast.LabeledStmt:
+		c.stmt(s.Stmt, level)
+	case *ast.BlockStmt:
+		c.block(s, level)
+	case nil:
+	default:
+		c.exprs(s, level)
+	}
+}
+
+// ifChain walks an if and its else-if / else branches, which score +1 each
+// without a nesting increment.
+func (c *cognitive) ifChain(s *ast.IfStmt, level int) {
+	c.stmt(s.Init, level)
+	c.expr(s.Cond, level)
+	c.nested(level + 1)
+	c.block(s.Body, level+1)
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		c.score++
+		c.ifChain(e, level)
+	case *ast.BlockStmt:
+		c.score++
+		c.block(e, level+1)
+	}
+}
+
+func (c *cognitive) clauses(b *ast.BlockStmt, level int) {
+	c.nested(level)
+	for _, cl := range b.List {
+		switch cl := cl.(type) {
+		case *ast.CaseClause:
+			for _, e := range cl.List {
+				c.expr(e, level)
+			}
+			for _, s := range cl.Body {
+				c.stmt(s, level)
+			}
+		case *ast.CommClause:
+			c.stmt(cl.Comm, level)
+			for _, s := range cl.Body {
+				c.stmt(s, level)
+			}
+		}
+	}
+}
+
+// exprs scores the expressions of a simple statement.
+func (c *cognitive) exprs(s ast.Stmt, level int) {
+	ast.Inspect(s, func(n ast.Node) bool {
+		if e, ok := n.(ast.Expr); ok {
+			c.expr(e, level)
+			return false
+		}
+		return true
+	})
+}
+
+func (c *cognitive) expr(e ast.Expr, level int) {
+	if e == nil {
+		return
+	}
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			c.nested(level + 1)
+			c.block(n.Body, level+1)
+			return false
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				c.score += boolRuns(n)
+				c.boolOperands(n, level)
+				return false
+			}
+		case *ast.CallExpr:
+			if c.recursive(n) {
+				c.score++
+			}
+		}
+		return true
+	})
+}
+
+// boolOperands scores the operands of a && / || chain; a parenthesized
+// chain starts a run of its own.
+func (c *cognitive) boolOperands(e ast.Expr, level int) {
+	if b, ok := e.(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+		c.boolOperands(b.X, level)
+		c.boolOperands(b.Y, level)
+		return
+	}
+	c.expr(e, level)
+}
+
+// boolRuns counts runs of like operators in a && / || expression read left
+// to right: a && b && c is 1, a && b || c is 2.
+func boolRuns(e *ast.BinaryExpr) int {
+	var ops []token.Token
+	var flatten func(ast.Expr)
+	flatten = func(x ast.Expr) {
+		b, ok := x.(*ast.BinaryExpr)
+		if !ok || (b.Op != token.LAND && b.Op != token.LOR) {
+			return
+		}
+		flatten(b.X)
+		ops = append(ops, b.Op)
+		flatten(b.Y)
+	}
+	flatten(e)
+	runs := 0
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			runs++
+		}
+	}
+	return runs
+}
+
+func (c *cognitive) recursive(call *ast.CallExpr) bool {
+	if c.self.name == "" {
+		return false
+	}
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		return c.self.recv == "" && f.Name == c.self.name
+	case *ast.SelectorExpr:
+		x, ok := f.X.(*ast.Ident)
+		return ok && c.self.recv != "" && x.Name == c.self.recv && f.Sel.Name == c.self.name
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package concurrency
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+const (
+	syncPath     = "sync"
+	errgroupPath = "golang.org/x/sync/errgroup"
+	contextPath  = "context"
+
+	maxContextCalls = 10
+)
+
+// methods lists, per kind, the calls recorded on a primitive.
+var methods = map[string]map[string]bool{
+	model.SyncMutex:     set("Lock", "Unlock", "TryLock"),
+	model.SyncRWMutex:   set("Lock", "Unlock", "TryLock", "RLock", "RUnlock", "TryRLock"),
+	model.SyncWaitGroup: set("Add", "Done", "Wait", "Go"),
+	model.SyncErrGroup:  set("Go", "TryGo", "Wait", "SetLimit"),
+	model.SyncLocker:    set("Lock", "Unlock", "RLock", "RUnlock"),
+}
+
+// Analyze summarizes the concurrency constructs of a *ast.FuncDecl or
+// *ast.FuncLit of file f. Calls are tied to a sync.Mutex, sync.RWMutex,
+// sync.WaitGroup or errgroup.Group by the called method's receiver type, which
+// also covers primitives declared in other files and promoted methods. Files
+// without type information go by kinds instead, the names the file gives
+// primitives (see fileKinds). Argument-less Lock / Unlock calls on anything
+// else are taken for a sync.Locker.
+func Analyze(fn ast.Node, f *enrichers.SyntaxFile, kinds map[string]string) *model.Concurrency {
+	var (
+		typ  *ast.FuncType
+		body *ast.BlockStmt
+	)
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		typ, body = fn.Type, fn.Body
+	case *ast.FuncLit:
+		typ, body = fn.Type, fn.Body
+	}
+	out := &model.Concurrency{}
+	if body == nil {
+		return out
+	}
+	a := &analyzer{info: f.Info, kinds: kinds, imports: f.Imports(), out: out, uses: map[string]*syncUse{}}
+	a.context(typ)
+
+	ast.Inspect(body, a.visit)
+	for _, u := range a.order {
+		if u.kind != model.SyncWaitGroup && u.kind != model.SyncErrGroup && u.locks != u.unlocks {
+			u.use.Unpaired = true
+		}
+		out.Sync = append(out.Sync, u.use)
+	}
+	return out
+}
+
+// empty reports whether c records nothing.
+func empty(c *model.Concurrency) bool {
+	return c.Goroutines == 0 && c.Channels == 0 && c.Sends == 0 && c.Receives == 0 &&
+		c.Closes == 0 && c.Selects == 0 && len(c.Sync) == 0 && c.Context == nil
+}
+
+type analyzer struct {
+	info    *types.Info // nil for files parsed from text
+	kinds   map[string]string
+	imports map[string]string
+	out     *model.Concurrency
+
+	uses  map[string]*syncUse // by expression
+	order []*syncUse
+
+	ctxNames map[string]bool // the context parameter and contexts derived from it
+	deferred int             // inside a deferred call
+}
+
+type syncUse struct {
+	use            model.SyncUse
+	kind           string
+	locks, unlocks int
+}
+
+func (a *analyzer) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.DeferStmt:
+		// defer mu.Unlock(), and calls inside defer func() { ... }()
+		a.deferred++
+		a.call(n.Call)
+		ast.Inspect(n.Call.Fun, a.visit)
+		for _, arg := range n.Call.Args {
+			ast.Inspect(arg, a.visit)
+		}
+		a.deferred--
+		return false
+	case *ast.GoStmt:
+		a.out.Goroutines++
+	case *ast.SendStmt:
+		a.out.Sends++
+	case *ast.UnaryExpr:
+		if n.Op == token.ARROW {
+			a.out.Receives++
+		}
+	case *ast.SelectStmt:
+		a.out.Selects++
+	case *ast.AssignStmt:
+		a.derive(n)
+	case *ast.CallExpr:
+		a.call(n)
+	}
+	return true
+}
+
+// context finds a context.Context parameter.
+func (a *analyzer) context(typ *ast.FuncType) {
+	if typ == nil || typ.Params == nil {
+		return
+	}
+	for _, f := range typ.Params.List {
+		if pkg, name := a.qualified(f.Type); pkg != contextPath || name != "Context" || len(f.Names) == 0 {
+			continue
+		}
+		if n := f.Names[0].Name; n != "_" {
+			a.out.Context = &model.ContextUse{Param: n}
+			a.ctxNames = map[string]bool{n: true}
+			return
+		}
+	}
+}
+
+// derive follows contexts derived from the parameter: c, cancel :=
+// context.WithTimeout(ctx, d) and g, c := errgroup.WithContext(ctx).
+func (a *analyzer) derive(as *ast.AssignStmt) {
+	if a.ctxNames == nil || len(as.Rhs) != 1 || len(as.Lhs) == 0 {
+		return
+	}
+	call, ok := as.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 || !a.isCtx(call.Args[0]) {
+		return
+	}
+	derived := -1
+	switch pkg, name := a.qualified(call.Fun); {
+	case pkg == contextPath && strings.HasPrefix(name, "With"):
+		derived = 0
+	case pkg == errgroupPath && name == "WithContext":
+		derived = 1 // g, ctx := errgroup.WithContext(ctx)
+	}
+	if derived < 0 || derived >= len(as.Lhs) {
+		return
+	}
+	if id, ok := as.Lhs[derived].(*ast.Ident); ok && id.Name != "_" {
+		a.ctxNames[id.Name] = true
+	}
+}
+
+func (a *analyzer) isCtx(e ast.Expr) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && a.ctxNames[id.Name]
+}
+
+func (a *analyzer) call(c *ast.CallExpr) {
+	switch fun := c.Fun.(type) {
+	case *ast.Ident:
+		switch {
+		case fun.Name == "close" && fun.Obj == nil && len(c.Args) == 1:
+			a.out.Closes++
+		case fun.Name == "make" && fun.Obj == nil && len(c.Args) > 0:
+			if _, ok := c.Args[0].(*ast.ChanType); ok {
+				a.out.Channels++
+			}
+		}
+	case *ast.SelectorExpr:
+		a.method(fun, len(c.Args))
+	}
+	a.threads(c)
+}
+
+// method records a call on a sync primitive: mu.Lock(), wg.Add(1), g.Go(f).
+func (a *analyzer) method(sel *ast.SelectorExpr, nargs int) {
+	if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil && a.imports[x.Name] != "" {
+		return // package-qualified call
+	}
+	expr := types.ExprString(sel.X)
+	kind, typed := a.typedKind(sel)
+	if !typed {
+		kind = a.kinds[lastName(sel.X)]
+	}
+	if kind == "" {
+		if nargs != 0 || !methods[model.SyncLocker][sel.Sel.Name] {
+			return
+		}
+		kind = model.SyncLocker
+	}
+	name := sel.Sel.Name
+	if !methods[kind][name] {
+		return
+	}
+	u := a.uses[expr]
+	if u == nil {
+		u = &syncUse{kind: kind, use: model.SyncUse{Expr: expr, Kind: kind}}
+		a.uses[expr] = u
+		a.order = append(a.order, u)
+	}
+	if kind == model.SyncLocker && (name == "RLock" || name == "RUnlock") {
+		u.use.Kind = model.SyncRWMutex
+	}
+	add(&u.use.Calls, name)
+	if a.deferred > 0 {
+		add(&u.use.Deferred, name)
+	}
+	switch name {
+	case "Lock", "RLock":
+		u.locks++
+	case "Unlock", "RUnlock":
+		u.unlocks++
+	}
+}
+
+// typedKind classifies the primitive a method call is on by the method's
+// receiver type ("" if it is none); typed is false without type information
+// for the call.
+func (a *analyzer) typedKind(sel *ast.SelectorExpr) (kind string, typed bool) {
+	if a.info == nil {
+		return "", false
+	}
+	fn, ok := a.info.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return "", a.info.TypeOf(sel.X) != nil
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return "", true
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := types.Unalias(t).(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return "", true
+	}
+	switch path, name := n.Obj().Pkg().Path(), n.Obj().Name(); {
+	case path == syncPath && name == "Mutex":
+		return model.SyncMutex, true
+	case path == syncPath && name == "RWMutex":
+		return model.SyncRWMutex, true
+	case path == syncPath && name == "WaitGroup":
+		return model.SyncWaitGroup, true
+	case path == syncPath && name == "Locker":
+		return model.SyncLocker, true
+	case path == errgroupPath && name == "Group":
+		return model.SyncErrGroup, true
+	}
+	return "", true
+}
+
+// threads records a call the context is passed to; ctx.Done() and ctx.Err()
+// are checks.
+func (a *analyzer) threads(c *ast.CallExpr) {
+	cu := a.out.Context
+	if cu == nil {
+		return
+	}
+	if sel, ok := c.Fun.(*ast.SelectorExpr); ok && a.isCtx(sel.X) {
+		if sel.Sel.Name == "Done" || sel.Sel.Name == "Err" {
+			cu.Checks = true
+		}
+		return
+	}
+	for _, arg := range c.Args {
+		if !a.isCtx(arg) {
+			continue
+		}
+		if pkg, _ := a.qualified(c.Fun); pkg == contextPath {
+			return // deriving, not threading
+		}
+		cu.Threaded = true
+		if len(cu.Calls) < maxContextCalls {
+			add(&cu.Calls, types.ExprString(c.Fun))
+		}
+		return
+	}
+}
+
+// qualified splits "pkg.Name" (possibly behind a *) into the import path of
+// pkg and Name.
+func (a *analyzer) qualified(e ast.Expr) (string, string) {
+	return qualified(e, a.imports)
+}
+
+func qualified(e ast.Expr, imports map[string]string) (string, string) {
+	if s, ok := e.(*ast.StarExpr); ok {
+		e = s.X
+	}
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || x.Obj != nil {
+		return "", ""
+	}
+	return imports[x.Name], sel.Sel.Name
+}
+
+// lastName is the name a primitive is looked up by: "mu" for c.mu, s.state.mu and mu.
+func lastName(e ast.Expr) string {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.StarExpr:
+		return lastName(e.X)
+	}
+	return ""
+}
+
+func set(names ...string) map[string]bool {
+	out := make(map[string]bool, len(names))
+	for _, n := range names {
+		out[n] = true
+	}
+	return out
+}
+
+func add(list *[]string, s string) {
+	for _, have := range *list {
+		if have == s {
+			return
+		}
+	}
+	*list = append(*list, s)
+}
@@ -0,0 +1,157 @@
+package concurrency
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"sync"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// Enricher attaches model.Concurrency to functions that start goroutines,
+// use channels, select, sync primitives or errgroups, or take a
+// context.Context. It reads each file's syntax, typed when the reader loaded
+// it, through the repo's shared enrichers.Syntax.
+type Enricher struct {
+	workers int
+}
+
+func New() *Enricher { return &Enricher{} }
+
+// WithWorkers sets how many functions are analyzed concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectConcurrent }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	files := &fileCache{syntax: enrichers.SyntaxOf(repo), byPath: map[string]*parsedFile{}}
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		p := files.get(f)
+		node := p.Func(fn)
+		if node == nil {
+			return nil
+		}
+		if c := Analyze(node, p.SyntaxFile, p.kinds); !empty(c) {
+			fn.Aspects[core.AspectConcurrent] = c
+		}
+		return nil
+	})
+}
+
+// parsedFile adds the file-wide names Analyze needs without type information
+// to a shared syntax file.
+type parsedFile struct {
+	*enrichers.SyntaxFile
+	once  sync.Once
+	kinds map[string]string // name -> sync primitive kind
+}
+
+// fileCache collects each file's names once, whichever function asks first.
+type fileCache struct {
+	syntax *enrichers.Syntax
+	mu     sync.Mutex
+	byPath map[string]*parsedFile
+}
+
+func (c *fileCache) get(f *core.FileNode) *parsedFile {
+	c.mu.Lock()
+	p := c.byPath[f.RelPath]
+	if p == nil {
+		p = &parsedFile{SyntaxFile: c.syntax.File(f.RelPath)}
+		c.byPath[f.RelPath] = p
+	}
+	c.mu.Unlock()
+	p.once.Do(func() {
+		if p.AST == nil || p.Info != nil {
+			return
+		}
+		p.kinds = fileKinds(p.AST, p.Imports())
+	})
+	return p
+}
+
+// fileKinds finds the names the file gives sync primitives: struct fields,
+// parameters and variables typed sync.Mutex, sync.RWMutex, sync.WaitGroup or
+// errgroup.Group (pointers included), or assigned one (&sync.WaitGroup{},
+// new(sync.Mutex), errgroup.WithContext(ctx)). Embedded fields go by their
+// type name, as c.Mutex. Names are not scoped: the file is the unit. It is
+// the fallback for files without type information.
+func fileKinds(f *ast.File, imports map[string]string) map[string]string {
+	typeKind := func(t ast.Expr) string {
+		switch pkg, name := qualified(t, imports); {
+		case pkg == syncPath && name == "Mutex":
+			return model.SyncMutex
+		case pkg == syncPath && name == "RWMutex":
+			return model.SyncRWMutex
+		case pkg == syncPath && name == "WaitGroup":
+			return model.SyncWaitGroup
+		case pkg == errgroupPath && name == "Group":
+			return model.SyncErrGroup
+		}
+		return ""
+	}
+	valueKind := func(v ast.Expr) string {
+		switch v := ast.Unparen(v).(type) {
+		case *ast.UnaryExpr:
+			if lit, ok := v.X.(*ast.CompositeLit); ok && v.Op == token.AND {
+				return typeKind(lit.Type)
+			}
+		case *ast.CompositeLit:
+			return typeKind(v.Type)
+		case *ast.CallExpr:
+			if id, ok := v.Fun.(*ast.Ident); ok && id.Name == "new" && len(v.Args) == 1 {
+				return typeKind(v.Args[0])
+			}
+			if pkg, name := qualified(v.Fun, imports); pkg == errgroupPath && name == "WithContext" {
+				return model.SyncErrGroup
+			}
+		}
+		return ""
+	}
+
+	out := map[string]string{}
+	name := func(e ast.Expr, kind string) {
+		if id, ok := e.(*ast.Ident); ok && kind != "" && id.Name != "_" {
+			out[id.Name] = kind
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			kind := typeKind(n.Type)
+			for _, id := range n.Names {
+				name(id, kind)
+			}
+			if len(n.Names) == 0 && kind != "" {
+				_, typ := qualified(n.Type, imports)
+				out[typ] = kind
+			}
+		case *ast.ValueSpec:
+			for i, id := range n.Names {
+				switch {
+				case n.Type != nil:
+					name(id, typeKind(n.Type))
+				case i < len(n.Values):
+					name(id, valueKind(n.Values[i]))
+				}
+			}
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				if i < len(n.Lhs) {
+					name(n.Lhs[i], valueKind(rhs))
+				}
+			}
+		}
+		return true
+	})
+	return out
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"go/types"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
 
@@ -26,6 +29,9 @@ type Config struct {
 	MaxRefs     int
 	MaxLines    int
 	Counterpart map[string][]string
+
+	Tokenizer tokenizer.Counter // optional; enables token counts and MaxTokens
+	MaxTokens int               // per snippet, applied after MaxLines (0 = none)
 }
 
 func (c Config) withDefaults() Config {
@@ -46,19 +52,26 @@ func (c Config) withDefaults() Config {
 }
 
 type Enricher struct {
-	cfg Config
-	idx *Index
+	cfg     Config
+	idx     *Index
+	workers int
 }
 
 func New(cfg Config, idx *Index) *Enricher {
 	return &Enricher{cfg: cfg.withDefaults(), idx: idx}
 }
 
+// WithWorkers sets how many functions are resolved concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
 func (e *Enricher) Kind() core.AspectKind { return core.AspectCtxRefs }
 
 // ---------- Public API ----------
 
-func (e *Enricher) Enrich(_ context.Context, repo *core.RepoNode) error {
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
 	if repo == nil || e.idx == nil {
 		return nil
 	}
@@ -69,29 +82,58 @@ func (e *Enricher) Enrich(_ context.Context, repo *core.RepoNode) error {
 			fileMap[norm(f.RelPath)] = f
 		}
 	}
+	// snippets may come from files outside this batch (streaming mode)
+	linesOf := func(rel string) []string {
+		if f := fileMap[rel]; f != nil {
+			return f.Lines
+		}
+		if repo.Sources != nil {
+			return repo.Sources(rel)
+		}
+		return nil
+	}
 
+	// interface type records: who implements them, all by name and the first
+	// MaxRefs as snippets
 	for _, f := range repo.Files {
-		if f == nil || len(f.Functions) == 0 {
+		if f == nil {
 			continue
 		}
-		for _, fn := range f.Functions {
-			refs := e.computeForFunction(fileMap, f, fn)
-			if len(refs) == 0 {
+		for _, t := range f.Types {
+			if t.Kind != core.TypeInterface {
 				continue
 			}
-			if fn.Aspects == nil {
-				fn.Aspects = make(map[core.AspectKind]any, 1)
+			impls, refs := e.implementersRef(linesOf, f, t)
+			if len(impls) == 0 {
+				continue
+			}
+			if t.Aspects == nil {
+				t.Aspects = make(map[core.AspectKind]any, 2)
+			}
+			t.Aspects[core.AspectImplements] = impls
+			if len(refs) > 0 {
+				t.Aspects[core.AspectCtxRefs] = refs
 			}
-			fn.Aspects[core.AspectCtxRefs] = refs
 		}
 	}
-	return nil
+
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		refs := e.computeForFunction(linesOf, f, fn)
+		if len(refs) == 0 {
+			return nil
+		}
+		if fn.Aspects == nil {
+			fn.Aspects = make(map[core.AspectKind]any, 1)
+		}
+		fn.Aspects[core.AspectCtxRefs] = refs
+		return nil
+	})
 }
 
 // ---------- Main dispatcher ----------
 
 func (e *Enricher) computeForFunction(
-	files map[string]*core.FileNode,
+	files lineSource,
 	file *core.FileNode,
 	fn *core.FunctionNode,
 ) []*model.ContextRef {
@@ -110,16 +152,26 @@ func (e *Enricher) computeForFunction(
 	refs = dedupRefs(refs)
 	refs = stableOrder(refs)
 
-	if len(refs) > e.cfg.MaxRefs {
-		refs = refs[:e.cfg.MaxRefs]
+	// a method satisfying an interface keeps a slot for another implementation
+	// (unless that is the only slot), giving up the receiver type first
+	var other []*model.ContextRef
+	if e.cfg.MaxRefs > 1 {
+		other = e.otherImplementationRef(files, recvT, pkgPath, fn.Name)
+	}
+	limit := e.cfg.MaxRefs - len(other)
+	if len(refs) > limit {
+		refs = slices.DeleteFunc(refs, func(r *model.ContextRef) bool { return r.Kind == "receiver_type" })
 	}
-	return refs
+	if len(refs) > limit {
+		refs = refs[:limit]
+	}
+	return append(refs, other...)
 }
 
 // ---------- Section helpers ----------
 
 // 1) Receiver type definition
-func (e *Enricher) receiverTypeRef(files map[string]*core.FileNode, recvT *types.Named) []*model.ContextRef {
+func (e *Enricher) receiverTypeRef(files lineSource, recvT *types.Named) []*model.ContextRef {
 	td, ok := e.idx.ReceiverDecl(recvT)
 	if !ok {
 		return nil
@@ -134,7 +186,7 @@ func (e *Enricher) receiverTypeRef(files map[string]*core.FileNode, recvT *types
 
 // 2) Interface methods declaring this function
 func (e *Enricher) interfaceMethodRef(
-	files map[string]*core.FileNode,
+	files lineSource,
 	recvT *types.Named,
 	pkgPath, fnName string,
 ) []*model.ContextRef {
@@ -163,10 +215,74 @@ func (e *Enricher) interfaceMethodRef(
 	return nil
 }
 
+// 2b) The same interface method implemented on another type
+func (e *Enricher) otherImplementationRef(
+	files lineSource,
+	recvT *types.Named,
+	pkgPath, fnName string,
+) []*model.ContextRef {
+	ifaces := e.idx.ImplementedInterfacesDeclaring(recvT, fnName)
+	sort.SliceStable(ifaces, func(i, j int) bool {
+		if (ifaces[i].PkgPath == pkgPath) != (ifaces[j].PkgPath == pkgPath) {
+			return ifaces[i].PkgPath == pkgPath
+		}
+		if ifaces[i].FilePath != ifaces[j].FilePath {
+			return ifaces[i].FilePath < ifaces[j].FilePath
+		}
+		return ifaces[i].Name < ifaces[j].Name
+	})
+	own, _ := e.idx.MethodOn(TypeDecl{PkgPath: pkgPath, Name: recvT.Obj().Name()}, fnName)
+	for _, idecl := range ifaces {
+		for _, impl := range e.idx.Implementers(idecl.PkgPath, idecl.Name) {
+			if impl.Type.PkgPath == pkgPath && impl.Type.Name == recvT.Obj().Name() {
+				continue
+			}
+			d, ok := e.idx.MethodOn(impl.Type, fnName)
+			if !ok || (d.FilePath == own.FilePath && d.StartLine == own.StartLine) {
+				continue // not in the repo, or promoted from the same embedded type
+			}
+			if cr, ok := e.slice(files, d.FilePath, d.StartLine, d.EndLine,
+				"implementers", impl.Type.Name+"."+fnName,
+				"Another implementation of "+idecl.Name+"."+fnName+" shows how the contract varies."); ok {
+				return []*model.ContextRef{cr}
+			}
+		}
+	}
+	return nil
+}
+
+// 2c) Implementers of an interface type
+func (e *Enricher) implementersRef(files lineSource, file *core.FileNode, t *core.TypeNode) ([]model.Implementer, []*model.ContextRef) {
+	idecl, ok := e.idx.InterfaceAt(norm(file.RelPath), t.Name)
+	if !ok {
+		return nil, nil
+	}
+	var (
+		impls []model.Implementer
+		refs  []*model.ContextRef
+	)
+	for _, impl := range e.idx.Implementers(idecl.PkgPath, idecl.Name) {
+		name := impl.Type.Name
+		if impl.Pointer {
+			name = "*" + name
+		}
+		impls = append(impls, model.Implementer{Type: name, Path: impl.Type.FilePath, Line: impl.Type.StartLine})
+		if len(refs) == e.cfg.MaxRefs {
+			continue
+		}
+		if cr, ok := e.slice(files, impl.Type.FilePath, impl.Type.StartLine, impl.Type.EndLine,
+			"implementers", name,
+			name+" implements "+idecl.Name+"; its shape shows one way the contract is met."); ok {
+			refs = append(refs, cr)
+		}
+	}
+	return impls, refs
+}
+
 // 3) Counterpart methods on the same receiver
 // TODO pending setup Counterpart
 func (e *Enricher) counterpartMethodRef(
-	files map[string]*core.FileNode,
+	files lineSource,
 	file *core.FileNode,
 	recvT *types.Named,
 	fnName string,
@@ -195,7 +311,7 @@ func (e *Enricher) counterpartMethodRef(
 }
 
 // 4) Constructors for this type
-func (e *Enricher) constructorRef(files map[string]*core.FileNode, recvT *types.Named) []*model.ContextRef {
+func (e *Enricher) constructorRef(files lineSource, recvT *types.Named) []*model.ContextRef {
 	cons := e.idx.ConstructorsFor(recvT)
 	if len(cons) == 0 {
 		return nil
@@ -217,8 +333,11 @@ func (e *Enricher) constructorRef(files map[string]*core.FileNode, recvT *types.
 
 // ---------- Helpers ----------
 
+// lineSource returns the lines of a file by posix path relative to the repo root.
+type lineSource func(rel string) []string
+
 func (e *Enricher) slice(
-	files map[string]*core.FileNode,
+	files lineSource,
 	rel string,
 	start, end int,
 	kind, symbol, why string,
@@ -226,21 +345,26 @@ func (e *Enricher) slice(
 	if start < 1 || end < start {
 		return nil, false
 	}
-	f := files[norm(rel)]
-	if f == nil || len(f.Lines) == 0 {
-		return nil, false
-	}
-	if start > len(f.Lines) {
+	all := files(norm(rel))
+	if len(all) == 0 || start > len(all) {
 		return nil, false
 	}
-	maxEnd := utils.Min(len(f.Lines), start+e.cfg.MaxLines-1)
+	maxEnd := utils.Min(len(all), start+e.cfg.MaxLines-1)
 	if end > maxEnd {
 		end = maxEnd
 	}
-	code := strings.Join(f.Lines[start-1:end], "\n")
+	lines := all[start-1 : end]
+	if e.cfg.Tokenizer != nil && e.cfg.MaxTokens > 0 {
+		n := tokenizer.FitHead(e.cfg.Tokenizer, lines, e.cfg.MaxTokens)
+		if n == 0 {
+			return nil, false
+		}
+		lines, end = lines[:n], start+n-1
+	}
+	code := strings.Join(lines, "\n")
 	code = utils.NormalizeCode(code)
 
-	return &model.ContextRef{
+	cr := &model.ContextRef{
 		Path:      rel,
 		StartLine: start,
 		EndLine:   end,
@@ -248,7 +372,11 @@ func (e *Enricher) slice(
 		Kind:      kind,
 		Symbol:    symbol,
 		Why:       why,
-	}, true
+	}
+	if e.cfg.Tokenizer != nil {
+		cr.Tokens = e.cfg.Tokenizer.Count(code)
+	}
+	return cr, true
 }
 
 func dedupRefs(in []*model.ContextRef) []*model.ContextRef {
@@ -4,10 +4,13 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/workspace"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -47,6 +50,13 @@ type FuncDecl struct {
 	Results   []*types.Named
 }
 
+// Implementer is a concrete type of the repo implementing an interface,
+// through its own or promoted (embedded) methods.
+type Implementer struct {
+	Type    TypeDecl
+	Pointer bool // only *T implements it: some methods have pointer receivers
+}
+
 type Index struct {
 	repoRoot string
 	fset     *token.FileSet
@@ -62,13 +72,17 @@ type Index struct {
 
 	// fast lookup
 	typeDeclByPkgAndName map[string]map[string]TypeDecl // pkg -> name -> decl
+	funcDeclByObj        map[*types.Func]FuncDecl
+
+	// "pkg.I" -> concrete types implementing I
+	implementers map[string][]Implementer
 }
 
 // ------------------------------ Public entrypoint ------------------------------
 
 // Load builds a semantic index for all packages under repoRoot (./...).
-func Load(repoRoot string) (*Index, error) {
-	pkgs, err := loadPackages(repoRoot)
+func Load(repoRoot string, build buildcfg.Config) (*Index, error) {
+	pkgs, err := loadPackages(repoRoot, build)
 	if err != nil {
 		return nil, err
 	}
@@ -82,13 +96,19 @@ func Load(repoRoot string) (*Index, error) {
 		}
 		idx.indexPackage(p)
 	}
+	idx.indexImplementers()
 	return idx, nil
 }
 
 // ------------------------------ Construction helpers ------------------------------
 
-func loadPackages(repoRoot string) ([]*packages.Package, error) {
-	cfg := &packages.Config{
+func loadPackages(repoRoot string, build buildcfg.Config) ([]*packages.Package, error) {
+	ws, err := workspace.Discover(repoRoot, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Close()
+	cfg := build.Apply(&packages.Config{
 		Mode: packages.NeedName |
 			packages.NeedFiles |
 			packages.NeedCompiledGoFiles |
@@ -97,8 +117,9 @@ func loadPackages(repoRoot string) ([]*packages.Package, error) {
 			packages.NeedTypesInfo |
 			packages.NeedImports,
 		Dir: repoRoot,
-	}
-	return packages.Load(cfg, "./...")
+		Env: ws.Env(os.Environ()),
+	})
+	return packages.Load(cfg, ws.Patterns()...)
 }
 
 func newIndex(repoRoot string, pkgs []*packages.Package) *Index {
@@ -110,6 +131,8 @@ func newIndex(repoRoot string, pkgs []*packages.Package) *Index {
 		funcDeclsByPkg:       make(map[string][]FuncDecl),
 		funcDeclsByFile:      make(map[string][]FuncDecl),
 		typeDeclByPkgAndName: make(map[string]map[string]TypeDecl),
+		funcDeclByObj:        make(map[*types.Func]FuncDecl),
+		implementers:         make(map[string][]Implementer),
 	}
 	if len(pkgs) > 0 {
 		idx.fset = pkgs[0].Fset
@@ -207,6 +230,56 @@ func (idx *Index) handleFuncDecl(p *packages.Package, pkgPath, fileRel string, f
 
 	idx.funcDeclsByPkg[pkgPath] = append(idx.funcDeclsByPkg[pkgPath], fd)
 	idx.funcDeclsByFile[fileRel] = append(idx.funcDeclsByFile[fileRel], fd)
+	idx.funcDeclByObj[fnObj] = fd
+}
+
+// indexImplementers computes, for every interface declared in the repo, the
+// concrete repo types implementing it, as T or only as *T. Empty interfaces,
+// constraint interfaces and generic types are left out.
+func (idx *Index) indexImplementers() {
+	type named struct {
+		decl TypeDecl
+		t    *types.Named
+	}
+	var concrete []named
+	for _, pkg := range sortedKeys(idx.typeDeclByPkgAndName) {
+		for _, td := range idx.typeDeclsByPkg[pkg] {
+			t := idx.lookupNamed(td.PkgPath, td.Name)
+			if t == nil || t.TypeParams().Len() > 0 || types.IsInterface(t) {
+				continue
+			}
+			concrete = append(concrete, named{td, t})
+		}
+	}
+	for _, pkg := range sortedKeys(idx.ifaceDeclsByPkg) {
+		for _, idecl := range idx.ifaceDeclsByPkg[pkg] {
+			it, ok := idx.lookupInterface(idecl.PkgPath, idecl.Name)
+			if !ok || it.NumMethods() == 0 || !it.IsMethodSet() {
+				continue
+			}
+			if t := idx.lookupNamed(idecl.PkgPath, idecl.Name); t == nil || t.TypeParams().Len() > 0 {
+				continue
+			}
+			key := idecl.PkgPath + "." + idecl.Name
+			for _, c := range concrete {
+				switch {
+				case types.Implements(c.t, it):
+					idx.implementers[key] = append(idx.implementers[key], Implementer{Type: c.decl})
+				case types.Implements(types.NewPointer(c.t), it):
+					idx.implementers[key] = append(idx.implementers[key], Implementer{Type: c.decl, Pointer: true})
+				}
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
 }
 
 func (idx *Index) buildInterfaceDecl(
@@ -321,9 +394,14 @@ func (idx *Index) ResolveReceiverNamed(fileRel, methodName, recvHint string) (re
 			return fd.RecvType, p, true
 		}
 	}
-	// fallback: scan all (slower but safe)
-	for pkg, fds := range idx.funcDeclsByPkg {
-		for _, fd := range fds {
+	// fallback: scan all (slower but safe), in package order so the pick is stable
+	pkgs := make([]string, 0, len(idx.funcDeclsByPkg))
+	for pkg := range idx.funcDeclsByPkg {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		for _, fd := range idx.funcDeclsByPkg[pkg] {
 			if fd.Name == methodName && fd.RecvType != nil {
 				_, rn := idx.fqnNamed(fd.RecvType)
 				if recvHint == "" || rn == recvHint {
@@ -399,6 +477,66 @@ func (idx *Index) ImplementedInterfacesDeclaring(named *types.Named, methodName
 	return out
 }
 
+// InterfaceAt returns the interface declared as `name` in the file `fileRel`.
+func (idx *Index) InterfaceAt(fileRel, name string) (InterfaceDecl, bool) {
+	for _, ifaces := range idx.ifaceDeclsByPkg {
+		for _, idecl := range ifaces {
+			if idecl.FilePath == fileRel && idecl.Name == name {
+				return idecl, true
+			}
+		}
+	}
+	return InterfaceDecl{}, false
+}
+
+// Implementers returns the concrete repo types implementing the interface
+// `name` of `pkgPath`: same package first, then by file and line.
+func (idx *Index) Implementers(pkgPath, name string) []Implementer {
+	out := append([]Implementer(nil), idx.implementers[pkgPath+"."+name]...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Type, out[j].Type
+		if (a.PkgPath == pkgPath) != (b.PkgPath == pkgPath) {
+			return a.PkgPath == pkgPath
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+	return out
+}
+
+// MethodOn returns the declaration of method `name` as seen on `t`, following
+// promotion through embedded fields; ok is false when it is not declared in the repo.
+func (idx *Index) MethodOn(t TypeDecl, name string) (FuncDecl, bool) {
+	named := idx.lookupNamed(t.PkgPath, t.Name)
+	if named == nil {
+		return FuncDecl{}, false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), true, named.Obj().Pkg(), name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return FuncDecl{}, false
+	}
+	fd, ok := idx.funcDeclByObj[fn.Origin()]
+	return fd, ok
+}
+
+// lookupNamed returns the named type declared as `name` in `pkgPath`.
+func (idx *Index) lookupNamed(pkgPath, name string) *types.Named {
+	for _, p := range idx.pkgs {
+		if p == nil || p.Types == nil || p.PkgPath != pkgPath {
+			continue
+		}
+		if obj, ok := p.Types.Scope().Lookup(name).(*types.TypeName); ok {
+			t, _ := obj.Type().(*types.Named)
+			return t
+		}
+		return nil
+	}
+	return nil
+}
+
 // lookupInterface returns the *types.Interface for an interface declared as `name` in `pkgPath`.
 func (idx *Index) lookupInterface(pkgPath, name string) (*types.Interface, bool) {
 	if pkgPath == "" || name == "" {
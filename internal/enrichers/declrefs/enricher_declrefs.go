@@ -0,0 +1,163 @@
+package declrefs
+
+import (
+	"context"
+	"go/types"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+)
+
+const (
+	defaultMaxRefs  = 8
+	defaultMaxLines = 15
+	hardCapMaxLines = 60
+)
+
+type Config struct {
+	MaxRefs  int // per function; same-package refs first, then repo, then external (<= 0 = 8)
+	MaxLines int // per snippet (<= 0 = 15, capped at 60)
+
+	Tokenizer tokenizer.Counter // optional; enables token counts and MaxTokens
+	MaxTokens int               // per snippet, applied after MaxLines (0 = none)
+}
+
+func (c Config) withDefaults() Config {
+	out := c
+	if out.MaxRefs <= 0 {
+		out.MaxRefs = defaultMaxRefs
+	}
+	if out.MaxLines <= 0 {
+		out.MaxLines = defaultMaxLines
+	}
+	out.MaxLines = utils.Min(out.MaxLines, hardCapMaxLines)
+	return out
+}
+
+// Enricher lists the package-level consts, vars (sentinel errors, globals)
+// and types each function references, with the definition for those declared
+// in the repo.
+type Enricher struct {
+	cfg     Config
+	idx     *Index
+	workers int
+}
+
+func New(cfg Config, idx *Index) *Enricher {
+	return &Enricher{cfg: cfg.withDefaults(), idx: idx}
+}
+
+// WithWorkers sets how many functions are resolved concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectDeclRefs }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil || e.idx == nil {
+		return nil
+	}
+	fileMap := make(map[string]*core.FileNode, len(repo.Files))
+	for _, f := range repo.Files {
+		if f != nil {
+			fileMap[f.RelPath] = f
+		}
+	}
+	// definitions may live in files outside this batch (streaming mode)
+	linesOf := func(rel string) []string {
+		if f := fileMap[rel]; f != nil {
+			return f.Lines
+		}
+		if repo.Sources != nil {
+			return repo.Sources(rel)
+		}
+		return nil
+	}
+
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		key := funcKey{line: fn.StartLine, name: fn.Name}
+		if fn.Parent != "" {
+			key = funcKey{line: fn.StartLine, col: fn.StartCol}
+		}
+		targets := e.idx.uses[f.RelPath][key]
+		var refs []model.DeclRef
+		for _, scope := range []string{ScopePackage, ScopeRepo, ScopeExternal} {
+			for _, t := range targets {
+				if t.scope != scope || len(refs) == e.cfg.MaxRefs {
+					continue
+				}
+				if r, ok := e.ref(linesOf, t); ok {
+					refs = append(refs, r)
+				}
+			}
+		}
+		if len(refs) > 0 {
+			fn.Aspects[core.AspectDeclRefs] = refs
+		}
+		return nil
+	})
+}
+
+func (e *Enricher) ref(linesOf func(rel string) []string, t target) (model.DeclRef, bool) {
+	r := model.DeclRef{
+		Name:  t.obj.Name(),
+		Pkg:   t.obj.Pkg().Path(),
+		Kind:  kindOf(t.obj),
+		Scope: t.scope,
+	}
+	if t.site == nil {
+		r.Code = describe(t.obj)
+	} else {
+		all := linesOf(t.site.path)
+		if t.site.start < 1 || t.site.start > len(all) {
+			return r, false
+		}
+		end := min(t.site.end, len(all), t.site.start+e.cfg.MaxLines-1)
+		lines := all[t.site.start-1 : end]
+		if e.cfg.Tokenizer != nil && e.cfg.MaxTokens > 0 {
+			n := tokenizer.FitHead(e.cfg.Tokenizer, lines, e.cfg.MaxTokens)
+			if n == 0 {
+				return r, false
+			}
+			lines, end = lines[:n], t.site.start+n-1
+		}
+		r.Path, r.StartLine, r.EndLine = t.site.path, t.site.start, end
+		r.Code = utils.NormalizeCode(strings.Join(lines, "\n"))
+	}
+	if e.cfg.Tokenizer != nil {
+		r.Tokens = e.cfg.Tokenizer.Count(r.Code)
+	}
+	return r, true
+}
+
+func kindOf(obj types.Object) string {
+	switch obj.(type) {
+	case *types.Const:
+		return "const"
+	case *types.Var:
+		return "var"
+	default:
+		return "type"
+	}
+}
+
+// describe renders an external object on one line: "var io.EOF error",
+// "const math.MaxInt untyped int = ...", "type context.Context interface".
+// Struct and interface bodies are left out.
+func describe(obj types.Object) string {
+	if tn, ok := obj.(*types.TypeName); ok {
+		switch tn.Type().Underlying().(type) {
+		case *types.Struct:
+			return "type " + tn.Pkg().Path() + "." + tn.Name() + " struct"
+		case *types.Interface:
+			return "type " + tn.Pkg().Path() + "." + tn.Name() + " interface"
+		}
+	}
+	return types.ObjectString(obj, nil)
+}
@@ -0,0 +1,219 @@
+package declrefs
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/workspace"
+	"golang.org/x/tools/go/packages"
+)
+
+// Ref scopes (model.DeclRef.Scope).
+const (
+	ScopePackage  = "package"
+	ScopeRepo     = "repo"
+	ScopeExternal = "external"
+)
+
+// site is where a repo declaration lives: the spec, or the whole group for
+// grouped consts (iota needs its neighbors).
+type site struct {
+	path       string // posix, relative to the repo root
+	start, end int
+}
+
+// objKey identifies an object by the position of its name, so the copies
+// type-checked for a package and its test variant are one object.
+type objKey struct {
+	file      string
+	line, col int
+}
+
+// funcKey identifies a function: declarations by line and name, literals by
+// line and column of "func" (core.FunctionNode.StartCol).
+type funcKey struct {
+	line, col int
+	name      string
+}
+
+type target struct {
+	obj   types.Object
+	scope string
+	site  *site // nil for external objects
+}
+
+// Index maps every function of the repo to the package-level consts, vars
+// and types it references, in first-use order.
+type Index struct {
+	repoRoot string
+	uses     map[string]map[funcKey][]target // file -> function -> referenced objects
+}
+
+// Load type-checks the repo (all modules, see workspace.Discover) and indexes
+// declarations and references.
+func Load(repoRoot string, build buildcfg.Config) (*Index, error) {
+	repoRoot, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := workspace.Discover(repoRoot, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Close()
+	cfg := build.Apply(&packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedSyntax |
+			packages.NeedTypes |
+			packages.NeedTypesInfo |
+			packages.NeedImports,
+		Dir:   repoRoot,
+		Env:   ws.Env(os.Environ()),
+		Tests: true,
+	})
+	pkgs, err := packages.Load(cfg, ws.Patterns()...)
+	if err != nil {
+		return nil, err
+	}
+	// plain packages before their test variants, so shared files are indexed once
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return !strings.Contains(pkgs[i].ID, " [") && strings.Contains(pkgs[j].ID, " [")
+	})
+
+	idx := &Index{repoRoot: repoRoot, uses: map[string]map[funcKey][]target{}}
+	sites := map[objKey]*site{}
+	for _, p := range pkgs {
+		for i, f := range p.Syntax {
+			if f != nil && i < len(p.CompiledGoFiles) {
+				idx.indexDecls(p.Fset, f, idx.rel(p.CompiledGoFiles[i]), sites)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, p := range pkgs {
+		if p.TypesInfo == nil {
+			continue
+		}
+		for i, f := range p.Syntax {
+			if f == nil || i >= len(p.CompiledGoFiles) || seen[p.CompiledGoFiles[i]] {
+				continue
+			}
+			seen[p.CompiledGoFiles[i]] = true
+			idx.indexUses(p, f, idx.rel(p.CompiledGoFiles[i]), sites)
+		}
+	}
+	return idx, nil
+}
+
+// indexDecls records the site of every package-level const, var and type of f.
+func (idx *Index) indexDecls(fset *token.FileSet, f *ast.File, rel string, sites map[objKey]*site) {
+	lines := func(from, to token.Pos) *site {
+		return &site{path: rel, start: fset.Position(from).Line, end: fset.Position(to).Line}
+	}
+	key := func(id *ast.Ident) objKey {
+		pos := fset.Position(id.Pos())
+		return objKey{rel, pos.Line, pos.Column}
+	}
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok == token.IMPORT {
+			continue
+		}
+		grouped := gd.Lparen.IsValid()
+		for _, spec := range gd.Specs {
+			s := lines(spec.Pos(), spec.End())
+			switch {
+			case !grouped:
+				s = lines(gd.Pos(), gd.End())
+			case gd.Tok == token.CONST:
+				s = lines(gd.Pos(), gd.End())
+			}
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				sites[key(spec.Name)] = s
+			case *ast.ValueSpec:
+				for _, n := range spec.Names {
+					if n.Name != "_" {
+						sites[key(n)] = s
+					}
+				}
+			}
+		}
+	}
+}
+
+// indexUses records, for each function and function literal of f, the
+// package-level objects it references.
+func (idx *Index) indexUses(p *packages.Package, f *ast.File, rel string, sites map[objKey]*site) {
+	byFunc := idx.uses[rel]
+	if byFunc == nil {
+		byFunc = map[funcKey][]target{}
+		idx.uses[rel] = byFunc
+	}
+	collect := func(n ast.Node) []target {
+		var out []target
+		seen := map[types.Object]bool{}
+		ast.Inspect(n, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := p.TypesInfo.Uses[id]
+			if obj == nil || seen[obj] || !packageLevel(obj) {
+				return true
+			}
+			seen[obj] = true
+			t := target{obj: obj, scope: ScopeExternal}
+			if pos := p.Fset.Position(obj.Pos()); pos.IsValid() {
+				t.site = sites[objKey{idx.rel(pos.Filename), pos.Line, pos.Column}]
+			}
+			switch {
+			case obj.Pkg().Path() == p.PkgPath:
+				t.scope = ScopePackage
+			case t.site != nil:
+				t.scope = ScopeRepo
+			default:
+				t.site = nil
+			}
+			out = append(out, t)
+			return true
+		})
+		return out
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			byFunc[funcKey{line: p.Fset.Position(n.Pos()).Line, name: n.Name.Name}] = collect(n)
+		case *ast.FuncLit:
+			pos := p.Fset.Position(n.Pos())
+			byFunc[funcKey{line: pos.Line, col: pos.Column}] = collect(n)
+		}
+		return true
+	})
+}
+
+// packageLevel reports whether obj is a const, var or type declared at package scope.
+func packageLevel(obj types.Object) bool {
+	switch obj.(type) {
+	case *types.Const, *types.Var, *types.TypeName:
+	default:
+		return false
+	}
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
+
+func (idx *Index) rel(filename string) string {
+	r, err := filepath.Rel(idx.repoRoot, filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(r)
+}
@@ -4,9 +4,33 @@ import (
 	"context"
 
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
 
 type Enricher interface {
 	Kind() core.AspectKind
 	Enrich(ctx context.Context, repo *core.RepoNode) error
 }
+
+// ForEachFunction calls visit for every function in repo on up to workers
+// goroutines (<= 0 = one per CPU). visit may only write to its own function
+// (fn.Aspects), which keeps the output independent of scheduling. It stops at
+// the first error or when ctx is done.
+func ForEachFunction(ctx context.Context, repo *core.RepoNode, workers int, visit func(f *core.FileNode, fn *core.FunctionNode) error) error {
+	type item struct {
+		f  *core.FileNode
+		fn *core.FunctionNode
+	}
+	var items []item
+	for _, f := range repo.Files {
+		if f == nil {
+			continue
+		}
+		for _, fn := range f.Functions {
+			items = append(items, item{f, fn})
+		}
+	}
+	return utils.ForEach(ctx, len(items), workers, func(i int) error {
+		return visit(items[i].f, items[i].fn)
+	})
+}
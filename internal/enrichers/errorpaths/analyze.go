@@ -0,0 +1,275 @@
+package errorpaths
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+const maxPanicLen = 80
+
+// Analyze reads the error behavior of a *ast.FuncDecl or *ast.FuncLit of
+// file f. The file's imports recognize fmt.Errorf and errors.Is / errors.As
+// under any name.
+//
+// With type information, sentinels are package-level variables whose type
+// implements error and error types are the constructed types T for which T or
+// *T implements error. Files parsed from text fall back to the conventions:
+// Err* / err* sentinels (plus io.EOF-style EOF) that are not declared inside a
+// function of the file, and *Error / *Err types. Nested function literals
+// count toward the enclosing function, except for their return statements.
+func Analyze(fn ast.Node, f *enrichers.SyntaxFile) *model.Errors {
+	var body *ast.BlockStmt
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		body = fn.Body
+	case *ast.FuncLit:
+		body = fn.Body
+	}
+	out := &model.Errors{}
+	if body == nil {
+		return out
+	}
+	a := analyzer{file: f.AST, info: f.Info, imports: f.Imports(), out: out, addressed: map[*ast.CompositeLit]bool{}}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			a.nested(n.Body)
+			return false
+		case *ast.ReturnStmt:
+			for _, r := range n.Results {
+				if s, ok := a.sentinel(r); ok {
+					add(&out.Sentinels, s)
+				}
+			}
+		}
+		return a.fact(n)
+	})
+	return out
+}
+
+// empty reports whether e records nothing.
+func empty(e *model.Errors) bool {
+	return len(e.Sentinels) == 0 && len(e.Types) == 0 && len(e.Wraps) == 0 &&
+		len(e.Is) == 0 && len(e.As) == 0 && len(e.Panics) == 0 && len(e.Callees) == 0
+}
+
+type analyzer struct {
+	file      *ast.File
+	info      *types.Info // nil for files parsed from text
+	imports   map[string]string
+	out       *model.Errors
+	addressed map[*ast.CompositeLit]bool // operands of &
+}
+
+// nested collects the facts of a function literal, whose returns are its own.
+func (a *analyzer) nested(b *ast.BlockStmt) {
+	ast.Inspect(b, a.fact)
+}
+
+// fact records constructed error types, %w wrapping, errors.Is / errors.As
+// checks and panics. It never stops the walk.
+func (a *analyzer) fact(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.UnaryExpr:
+		if lit, ok := n.X.(*ast.CompositeLit); ok && n.Op == token.AND {
+			if name, ok := a.errorType(lit); ok {
+				add(&a.out.Types, "*"+name)
+				a.addressed[lit] = true // visited next; keep only the pointer form
+			}
+		}
+	case *ast.CompositeLit:
+		if name, ok := a.errorType(n); ok && !a.addressed[n] {
+			add(&a.out.Types, name)
+		}
+	case *ast.CallExpr:
+		a.call(n)
+	}
+	return true
+}
+
+func (a *analyzer) call(c *ast.CallExpr) {
+	if id, ok := c.Fun.(*ast.Ident); ok {
+		if id.Name == "panic" && id.Obj == nil && len(c.Args) == 1 {
+			add(&a.out.Panics, clip(types.ExprString(c.Args[0])))
+		}
+		return
+	}
+	pkg, name := a.qualified(c.Fun)
+	switch {
+	case pkg == "fmt" && name == "Errorf" && len(c.Args) > 0:
+		lit, ok := c.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		format, err := strconv.Unquote(lit.Value)
+		if err != nil || !strings.Contains(format, "%w") {
+			return
+		}
+		add(&a.out.Wraps, format)
+		for _, arg := range c.Args[1:] {
+			if s, ok := a.sentinel(arg); ok {
+				add(&a.out.Sentinels, s)
+			}
+		}
+	case pkg == "errors" && name == "Is" && len(c.Args) == 2:
+		add(&a.out.Is, types.ExprString(c.Args[1]))
+	case pkg == "errors" && name == "As" && len(c.Args) == 2:
+		add(&a.out.As, a.targetType(c.Args[1]))
+	}
+}
+
+// qualified splits "pkg.Name" into the import path of pkg and Name.
+func (a *analyzer) qualified(e ast.Expr) (string, string) {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || x.Obj != nil {
+		return "", ""
+	}
+	return a.imports[x.Name], sel.Sel.Name
+}
+
+// sentinel recognizes a reference to a package-level error variable: ErrX,
+// pkg.ErrX, io.EOF.
+func (a *analyzer) sentinel(e ast.Expr) (string, bool) {
+	var (
+		id   *ast.Ident // the variable
+		name string
+	)
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id, name = e, e.Name
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok || x.Obj != nil {
+			return "", false
+		}
+		id, name = e.Sel, x.Name+"."+e.Sel.Name
+	default:
+		return "", false
+	}
+	if a.info != nil {
+		if obj := a.info.Uses[id]; obj != nil {
+			v, ok := obj.(*types.Var)
+			ok = ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() && types.Implements(v.Type(), errorIface)
+			return name, ok
+		}
+	}
+	if !sentinelName(id.Name) || a.local(id) {
+		return "", false
+	}
+	return name, true
+}
+
+// local reports whether id resolves to a declaration inside a function of the
+// file, such as a variable a closure captures.
+func (a *analyzer) local(id *ast.Ident) bool {
+	if id.Obj == nil || a.file == nil {
+		return false
+	}
+	pos := id.Obj.Pos()
+	if !pos.IsValid() {
+		return false
+	}
+	for _, d := range a.file.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && pos >= fd.Pos() && pos < fd.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// targetType names the type errors.As looks for from its &target argument,
+// through the target's declaration when it is a var with an explicit type.
+func (a *analyzer) targetType(e ast.Expr) string {
+	u, ok := ast.Unparen(e).(*ast.UnaryExpr)
+	if !ok || u.Op != token.AND {
+		return types.ExprString(e)
+	}
+	id, ok := ast.Unparen(u.X).(*ast.Ident)
+	if !ok || id.Obj == nil {
+		return types.ExprString(e)
+	}
+	if vs, ok := id.Obj.Decl.(*ast.ValueSpec); ok && vs.Type != nil {
+		return types.ExprString(vs.Type)
+	}
+	return types.ExprString(e)
+}
+
+// errorType reports whether a composite literal constructs an error type and
+// names it as written: PathError, pkg.SyntaxError, MyErr[T].
+func (a *analyzer) errorType(lit *ast.CompositeLit) (string, bool) {
+	if lit.Type == nil {
+		return "", false // element of an enclosing literal
+	}
+	if a.info != nil {
+		if t := a.info.TypeOf(lit); t != nil {
+			ok := types.Implements(t, errorIface) ||
+				!types.IsInterface(t) && types.Implements(types.NewPointer(t), errorIface)
+			return types.ExprString(lit.Type), ok
+		}
+	}
+	base := lit.Type
+	switch x := base.(type) {
+	case *ast.IndexExpr:
+		base = x.X
+	case *ast.IndexListExpr:
+		base = x.X
+	}
+	var name string
+	switch b := base.(type) {
+	case *ast.Ident:
+		name = b.Name
+	case *ast.SelectorExpr:
+		name = b.Sel.Name
+	default:
+		return "", false
+	}
+	if !strings.HasSuffix(name, "Error") && !strings.HasSuffix(name, "Err") {
+		return "", false
+	}
+	return types.ExprString(lit.Type), true
+}
+
+var errorIface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// sentinelName follows the ErrX / errX convention; EOF covers io.EOF and kin.
+func sentinelName(name string) bool {
+	if name == "EOF" {
+		return true
+	}
+	for _, prefix := range []string{"Err", "err"} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" {
+			r, _ := utf8.DecodeRuneInString(rest)
+			return unicode.IsUpper(r)
+		}
+	}
+	return false
+}
+
+func clip(s string) string {
+	if len(s) <= maxPanicLen {
+		return s
+	}
+	return s[:maxPanicLen] + "..."
+}
+
+func add(list *[]string, s string) {
+	for _, have := range *list {
+		if have == s {
+			return
+		}
+	}
+	*list = append(*list, s)
+}
@@ -0,0 +1,221 @@
+package errorpaths
+
+import (
+	"context"
+	"go/ast"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ncg "github.com/vd09-projects/techlead-llm-go-data-creater/internal/callgraph"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+const (
+	defaultMaxDepth   = 2
+	defaultMaxSources = 10
+	maxCallees        = 50
+)
+
+type Config struct {
+	RepoRoot   string
+	MaxDepth   int // callee levels searched for errors and panics (<= 0 = 2)
+	MaxSources int // callees listed per function (<= 0 = 10)
+}
+
+func (c Config) withDefaults() Config {
+	out := c
+	if out.MaxDepth <= 0 {
+		out.MaxDepth = defaultMaxDepth
+	}
+	if out.MaxSources <= 0 {
+		out.MaxSources = defaultMaxSources
+	}
+	return out
+}
+
+// Enricher attaches model.Errors to functions: the error behavior of the
+// function itself (see Analyze) and of the repo functions it calls, found
+// through the callgraph computer up to MaxDepth calls away.
+type Enricher struct {
+	cfg      Config
+	computer ncg.Computer
+	workers  int
+}
+
+// New takes the callgraph computer to share with the callgraph enricher; nil
+// skips callees.
+func New(cfg Config, computer ncg.Computer) *Enricher {
+	return &Enricher{cfg: cfg.withDefaults(), computer: computer}
+}
+
+// WithWorkers sets how many functions are analyzed concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectErrors }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	if e.computer != nil {
+		if err := e.computer.Init(e.cfg.RepoRoot); err != nil {
+			e.computer = nil // soft-fail: own behavior only
+		}
+	}
+	files := &fileCache{syntax: enrichers.SyntaxOf(repo), byPath: map[string]*parsedFile{}}
+
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		p := files.get(f.RelPath)
+		node := p.Func(fn)
+		if node == nil {
+			return nil
+		}
+		errs := Analyze(node, p.SyntaxFile)
+		if fn.Parent == "" {
+			errs.Callees = e.callees(files, f.RelPath, fn.Symbol())
+		}
+		if !empty(errs) {
+			fn.Aspects[core.AspectErrors] = errs
+		}
+		return nil
+	})
+}
+
+// callees walks the callgraph breadth-first from (rel, sym) and reports the
+// repo callees that return sentinels, construct error types, wrap or panic.
+func (e *Enricher) callees(files *fileCache, rel, sym string) []model.ErrorSource {
+	if e.computer == nil {
+		return nil
+	}
+	type target struct{ path, sym string }
+	var out []model.ErrorSource
+	seen := map[target]bool{{rel, sym}: true}
+	frontier := []target{{rel, sym}}
+	for depth := 1; depth <= e.cfg.MaxDepth && len(frontier) > 0; depth++ {
+		var next []target
+		for _, t := range frontier {
+			callees, err := e.computer.GetCallees(t.path, t.sym, maxCallees)
+			if err != nil {
+				continue
+			}
+			for _, c := range callees {
+				tc := target{c.Path, c.Symbol}
+				// closures are analyzed with their enclosing function
+				if seen[tc] || strings.Contains(c.Symbol, "$") || !inRepo(c.Path) {
+					continue
+				}
+				seen[tc] = true
+				next = append(next, tc)
+				if len(out) == e.cfg.MaxSources {
+					continue
+				}
+				if errs := files.get(c.Path).analyze(c.Symbol); errs != nil {
+					out = append(out, model.ErrorSource{
+						Symbol: c.Symbol, Path: c.Path, Depth: depth,
+						Sentinels: errs.Sentinels, Types: errs.Types,
+						Wraps: errs.Wraps, Panics: errs.Panics,
+					})
+				}
+			}
+		}
+		frontier = next
+	}
+	return out
+}
+
+// parsedFile adds to a shared syntax file what the analysis of its callees
+// needs: declarations by symbol and the behavior found so far.
+type parsedFile struct {
+	*enrichers.SyntaxFile
+	once  sync.Once
+	bySym map[string]*ast.FuncDecl // "F", "(*T).M"
+	mu    sync.Mutex
+	facts map[string]*model.Errors // by symbol; nil when nothing surfaces
+}
+
+// analyze returns the behavior a caller may see from the declared function
+// sym (sentinels, error types, wraps, panics), or nil if there is none.
+func (p *parsedFile) analyze(sym string) *model.Errors {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if errs, ok := p.facts[sym]; ok {
+		return errs
+	}
+	var errs *model.Errors
+	if d := p.bySym[sym]; d != nil {
+		errs = Analyze(d, p.SyntaxFile)
+		errs.Is, errs.As = nil, nil
+		if empty(errs) {
+			errs = nil
+		}
+	}
+	p.facts[sym] = errs
+	return errs
+}
+
+// fileCache indexes each file once, callers and callees alike (callees may
+// live in files outside this batch in streaming mode).
+type fileCache struct {
+	syntax *enrichers.Syntax
+	mu     sync.Mutex
+	byPath map[string]*parsedFile
+}
+
+func (c *fileCache) get(rel string) *parsedFile {
+	c.mu.Lock()
+	p := c.byPath[rel]
+	if p == nil {
+		p = &parsedFile{SyntaxFile: c.syntax.File(rel)}
+		c.byPath[rel] = p
+	}
+	c.mu.Unlock()
+	p.once.Do(func() {
+		p.bySym = map[string]*ast.FuncDecl{}
+		p.facts = map[string]*model.Errors{}
+		if p.AST == nil {
+			return
+		}
+		for _, d := range p.AST.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok {
+				p.bySym[declSymbol(fd)] = fd
+			}
+		}
+	})
+	return p
+}
+
+// declSymbol renders a declaration the way callgraph edges name it:
+// "F", "(T).M", "(*T).M" (type parameters dropped).
+func declSymbol(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+	t := d.Recv.List[0].Type
+	star := ""
+	if s, ok := t.(*ast.StarExpr); ok {
+		star, t = "*", s.X
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	id, ok := t.(*ast.Ident)
+	if !ok {
+		return d.Name.Name
+	}
+	return "(" + star + id.Name + ")." + d.Name.Name
+}
+
+// inRepo reports whether an edge path is repo-relative (callees in the
+// standard library or the module cache are not).
+func inRepo(rel string) bool {
+	return rel != "" && !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, "../")
+}
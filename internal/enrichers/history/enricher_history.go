@@ -0,0 +1,133 @@
+package history
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/gitutil"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+type Config struct {
+	RepoRoot string
+	Churn    bool // count commits per line range with git log -L (one git run per function)
+	Debug    bool
+}
+
+// Enricher attaches git authorship and churn (model.History) to functions,
+// from one `git blame` per file and, with Churn, one `git log -L` per
+// function. Outside a git checkout, and for untracked files or functions with
+// only uncommitted lines, it leaves the aspect unset.
+type Enricher struct {
+	cfg     Config
+	workers int
+}
+
+func New(cfg Config) *Enricher { return &Enricher{cfg: cfg} }
+
+// WithWorkers sets how many functions are enriched concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectHistory }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	now, err := gitutil.CommitTime(ctx, e.cfg.RepoRoot, "HEAD")
+	if err != nil {
+		if e.cfg.Debug {
+			log.Printf("history: %v", err)
+		}
+		return ctx.Err()
+	}
+
+	blames := &blameCache{root: e.cfg.RepoRoot, byPath: map[string]*blameEntry{}}
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		lines, err := blames.get(ctx, f.RelPath)
+		if err != nil || fn.StartLine < 1 || fn.EndLine > len(lines) {
+			if err != nil && e.cfg.Debug {
+				log.Printf("history: %v", err)
+			}
+			return ctx.Err()
+		}
+		h := summarize(lines[fn.StartLine-1:fn.EndLine], now)
+		if h == nil {
+			return nil
+		}
+		if e.cfg.Churn {
+			n, err := gitutil.RangeCommits(ctx, e.cfg.RepoRoot, f.RelPath, fn.StartLine, fn.EndLine)
+			if err != nil && e.cfg.Debug {
+				log.Printf("history: %v", err)
+			}
+			h.Commits = n
+		}
+		fn.Aspects[core.AspectHistory] = h
+		return ctx.Err()
+	})
+}
+
+// summarize folds a function's blame lines; nil if none is committed.
+func summarize(lines []gitutil.BlameLine, now time.Time) *model.History {
+	var newest, oldest *gitutil.BlameLine
+	authors := map[string]bool{}
+	for i := range lines {
+		l := &lines[i]
+		if l.Commit == "" {
+			continue
+		}
+		authors[l.Author] = true
+		if newest == nil || l.Time.After(newest.Time) || (l.Time.Equal(newest.Time) && l.Commit < newest.Commit) {
+			newest = l
+		}
+		if oldest == nil || l.Time.Before(oldest.Time) {
+			oldest = l
+		}
+	}
+	if newest == nil {
+		return nil
+	}
+	return &model.History{
+		LastCommit:   newest.Commit,
+		LastModified: newest.Time.Format(time.RFC3339),
+		Authors:      len(authors),
+		AgeDays:      days(now.Sub(oldest.Time)),
+		IdleDays:     days(now.Sub(newest.Time)),
+	}
+}
+
+func days(d time.Duration) int {
+	return max(0, int(d/(24*time.Hour)))
+}
+
+// blameCache runs git blame once per file, whichever function asks first.
+type blameCache struct {
+	root   string
+	mu     sync.Mutex
+	byPath map[string]*blameEntry
+}
+
+type blameEntry struct {
+	once  sync.Once
+	lines []gitutil.BlameLine
+	err   error
+}
+
+func (c *blameCache) get(ctx context.Context, rel string) ([]gitutil.BlameLine, error) {
+	c.mu.Lock()
+	be := c.byPath[rel]
+	if be == nil {
+		be = &blameEntry{}
+		c.byPath[rel] = be
+	}
+	c.mu.Unlock()
+	be.once.Do(func() { be.lines, be.err = gitutil.Blame(ctx, c.root, rel) })
+	return be.lines, be.err
+}
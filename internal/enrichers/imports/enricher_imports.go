@@ -0,0 +1,70 @@
+package imports
+
+import (
+	"context"
+	"go/ast"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// Enricher attaches the file imports (core.FileNode.Imports) each function
+// uses, in import order. A use is a qualified identifier, "pkg.Name", whose
+// qualifier the parser cannot resolve to a local declaration; dot imports are
+// never attributed.
+type Enricher struct {
+	workers int
+}
+
+func New() *Enricher { return &Enricher{} }
+
+// WithWorkers sets how many functions are resolved concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectImports }
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	syntax := enrichers.SyntaxOf(repo)
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		if len(f.Imports) == 0 {
+			return nil
+		}
+		node := syntax.FuncNode(f, fn)
+		if node == nil {
+			return nil
+		}
+		used := qualifiers(node)
+		var out []model.Import
+		for _, imp := range f.Imports {
+			if used[imp.Name] {
+				out = append(out, imp)
+			}
+		}
+		if len(out) > 0 {
+			fn.Aspects[core.AspectImports] = out
+		}
+		return nil
+	})
+}
+
+// qualifiers collects the unresolved identifiers n uses as selector operands:
+// package names, since locals and parameters resolve.
+func qualifiers(n ast.Node) map[string]bool {
+	out := map[string]bool{}
+	ast.Inspect(n, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				out[id.Name] = true
+			}
+		}
+		return true
+	})
+	return out
+}
@@ -5,42 +5,52 @@ import (
 	"strings"
 
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
 
 type Config struct {
 	Before int
 	After  int
+
+	Tokenizer tokenizer.Counter // optional; enables token counts and MaxTokens
+	MaxTokens int               // per snippet; lines farthest from the function are dropped first
 }
 
-type Enricher struct{ cfg Config }
+type Enricher struct {
+	cfg     Config
+	workers int
+}
 
 func New(cfg Config) *Enricher { return &Enricher{cfg: cfg} }
 
+// WithWorkers sets how many functions are enriched concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
 func (e *Enricher) Kind() core.AspectKind { return core.AspectNeighbors }
 
-func (e *Enricher) Enrich(_ context.Context, repo *core.RepoNode) error {
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
 	if repo == nil {
 		return nil
 	}
-	for _, f := range repo.Files {
-		if f == nil || len(f.Functions) == 0 {
-			continue
-		}
-		for _, fn := range f.Functions {
-			nbs := e.BuildNeighborsFromLines(
-				f.Lines, f.RelPath, fn.StartLine, fn.EndLine,
-			)
-			fn.Aspects[core.AspectNeighbors] = nbs
-		}
-	}
-	return nil
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		nbs := e.BuildNeighborsFromLines(
+			f.Lines, f.RelPath, fn.StartLine, fn.EndLine,
+		)
+		fn.Aspects[core.AspectNeighbors] = nbs
+		return nil
+	})
 }
 
 func (e *Enricher) BuildNeighborsFromLines(lines []string, relPath string, startLine, endLine int) []model.Neighbor {
 	before := utils.If(e.cfg.Before > 30, 30).Else(e.cfg.Before)
 	after := utils.If(e.cfg.After > 30, 30).Else(e.cfg.After)
+	cfg := e.cfg // e is shadowed by the line bounds below
 
 	if before == 0 && after == 0 {
 		return nil
@@ -51,21 +61,47 @@ func (e *Enricher) BuildNeighborsFromLines(lines []string, relPath string, start
 		s := utils.Max(1, startLine-before)
 		e := startLine - 1
 		if e >= s {
-			snip := strings.Join(lines[s-1:e], "\n")
-			if strings.TrimSpace(snip) != "" {
-				out = append(out, model.Neighbor{Path: relPath, StartLine: s, EndLine: e, Code: snip})
-			}
+			s += len(lines[s-1:e]) - cfg.fitTail(lines[s-1:e])
+			out = cfg.appendNeighbor(out, lines, relPath, s, e)
 		}
 	}
 	if after > 0 {
 		s := endLine + 1
 		e := utils.Min(len(lines), endLine+after)
 		if e >= s {
-			snip := strings.Join(lines[s-1:e], "\n")
-			if strings.TrimSpace(snip) != "" {
-				out = append(out, model.Neighbor{Path: relPath, StartLine: s, EndLine: e, Code: snip})
-			}
+			e = s - 1 + cfg.fitHead(lines[s-1:e])
+			out = cfg.appendNeighbor(out, lines, relPath, s, e)
 		}
 	}
 	return out
 }
+
+func (c Config) appendNeighbor(out []model.Neighbor, lines []string, relPath string, s, e int) []model.Neighbor {
+	if e < s {
+		return out
+	}
+	snip := strings.Join(lines[s-1:e], "\n")
+	if strings.TrimSpace(snip) == "" {
+		return out
+	}
+	nb := model.Neighbor{Path: relPath, StartLine: s, EndLine: e, Code: snip}
+	if c.Tokenizer != nil {
+		nb.Tokens = c.Tokenizer.Count(snip)
+	}
+	return append(out, nb)
+}
+
+// fitHead / fitTail return how many lines nearest the function fit the token budget.
+func (c Config) fitHead(lines []string) int {
+	if c.Tokenizer == nil || c.MaxTokens <= 0 {
+		return len(lines)
+	}
+	return tokenizer.FitHead(c.Tokenizer, lines, c.MaxTokens)
+}
+
+func (c Config) fitTail(lines []string) int {
+	if c.Tokenizer == nil || c.MaxTokens <= 0 {
+		return len(lines)
+	}
+	return tokenizer.FitTail(c.Tokenizer, lines, c.MaxTokens)
+}
@@ -3,22 +3,31 @@ package selection
 import (
 	"context"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 )
 
 type Enricher struct {
 	RepoRoot string
 	Strat    Strategy
+	Workers  int // functions scored concurrently (<= 0 = one per CPU)
 }
 
 func New(repoRoot string, strat Strategy) *Enricher {
 	if strat == nil {
-		strat = NewDefaultStrategy(repoRoot)
+		strat = NewDefaultStrategy(repoRoot, buildcfg.Config{})
 	}
 	return &Enricher{RepoRoot: repoRoot, Strat: strat}
 }
 
+// WithWorkers sets how many functions are scored concurrently.
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.Workers = n
+	return e
+}
+
 func (e *Enricher) Kind() core.AspectKind { return core.AspectSelection }
 
 func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
@@ -26,17 +35,12 @@ func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
 		return nil
 	}
 
-	for _, f := range repo.Files {
-		if f == nil || len(f.Functions) == 0 {
-			continue
-		}
-		for _, fn := range f.Functions {
-			fn.Aspects[core.AspectSelection] = &model.Selection{
-				Visibility: e.Strat.Visibility(f.RelPath, fn),
-				Reason:     e.Strat.ClassifyReason(f.RelPath, fn),
-				Score:      e.Strat.Score(f.RelPath, fn),
-			}
+	return enrichers.ForEachFunction(ctx, repo, e.Workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		fn.Aspects[core.AspectSelection] = &model.Selection{
+			Visibility: e.Strat.Visibility(f.RelPath, fn),
+			Reason:     e.Strat.ClassifyReason(f.RelPath, fn),
+			Score:      e.Strat.Score(f.RelPath, fn),
 		}
-	}
-	return nil
+		return nil
+	})
 }
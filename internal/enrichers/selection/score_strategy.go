@@ -2,12 +2,16 @@ package selection
 
 import (
 	"go/ast"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/workspace"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -21,15 +25,22 @@ type Strategy interface {
 
 type DefaultStrategy struct {
 	repoRoot    string
+	build       buildcfg.Config
 	nameToFanin map[string]int
 }
 
 func (ds *DefaultStrategy) precompute() {
-	cfg := &packages.Config{
+	ws, err := workspace.Discover(ds.repoRoot, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	cfg := ds.build.Apply(&packages.Config{
 		Mode: packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedCompiledGoFiles | packages.NeedName,
 		Dir:  ds.repoRoot,
-	}
-	pkgs, _ := packages.Load(cfg, "./...")
+		Env:  ws.Env(os.Environ()),
+	})
+	pkgs, _ := packages.Load(cfg, ws.Patterns()...)
 	nameToFanin := ds.countFanIn(pkgs)
 	ds.nameToFanin = nameToFanin
 }
@@ -142,12 +153,33 @@ func (ds *DefaultStrategy) Score(path string, fn *core.FunctionNode) float64 {
 	if isTest {
 		score -= 0.25
 	}
+	// git history, when the history enricher ran first: favor hot, maintained code
+	if h, ok := fn.Aspects[core.AspectHistory].(*model.History); ok {
+		if h.IdleDays <= 365 {
+			score += 0.05
+		} else if h.IdleDays > 3*365 {
+			score -= 0.05
+		}
+		if h.Commits >= 5 || h.Authors >= 3 {
+			score += 0.05
+		}
+	}
+	// structure, when the complexity enricher ran first: some branching is worth
+	// learning from, deeply tangled code is not
+	if cx, ok := fn.Aspects[core.AspectComplexity].(*model.Complexity); ok {
+		if cx.Cyclomatic >= 3 && cx.Cognitive <= 15 {
+			score += 0.05
+		} else if cx.Cognitive > 30 || cx.MaxNesting > 5 {
+			score -= 0.05
+		}
+	}
 	return utils.RoundN(utils.Clamp01(score), 2)
 }
 
-func NewDefaultStrategy(repoRoot string) Strategy {
+func NewDefaultStrategy(repoRoot string, build buildcfg.Config) Strategy {
 	ds := &DefaultStrategy{
 		repoRoot: repoRoot,
+		build:    build,
 	}
 	ds.precompute()
 	return ds
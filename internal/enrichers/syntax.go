@@ -0,0 +1,172 @@
+package enrichers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+)
+
+// Syntax hands out the syntax of repo files, each prepared once on demand:
+// the reader's typed tree when the FileNode carries one, else a parse of the
+// full source. Both resolve objects (identifiers the parser cannot resolve
+// within the file, such as package qualifiers, have a nil Obj). Enrichers of
+// the same repo share one (see SyntaxOf); it is safe for concurrent use.
+type Syntax struct {
+	files   map[string]*core.FileNode
+	sources func(rel string) []string // files outside the batch; may be nil
+
+	mu     sync.Mutex
+	byPath map[string]*SyntaxFile
+}
+
+// SyntaxFile is a parsed file, its functions by position and its imports.
+type SyntaxFile struct {
+	once    sync.Once
+	AST     *ast.File               // nil if the file is unreadable or does not parse
+	Info    *types.Info             // AST's type information; nil if it was parsed from text
+	imports map[string]string       // package name -> import path
+	decls   map[int]*ast.FuncDecl   // by line of "func"
+	lits    map[[2]int]*ast.FuncLit // by line and column of "func"
+}
+
+type syntaxKey struct{}
+
+// SyntaxOf returns the parse cache of repo. Files outside repo.Files are read
+// through repo.Sources (streaming mode).
+func SyntaxOf(repo *core.RepoNode) *Syntax {
+	return repo.Shared(syntaxKey{}, func() any {
+		files := make(map[string]*core.FileNode, len(repo.Files))
+		for _, f := range repo.Files {
+			if f != nil {
+				files[f.RelPath] = f
+			}
+		}
+		return &Syntax{files: files, sources: repo.Sources, byPath: map[string]*SyntaxFile{}}
+	}).(*Syntax)
+}
+
+// File prepares rel (posix, relative to the repo root) the first time it is asked for.
+func (s *Syntax) File(rel string) *SyntaxFile {
+	s.mu.Lock()
+	p := s.byPath[rel]
+	if p == nil {
+		p = &SyntaxFile{}
+		s.byPath[rel] = p
+	}
+	s.mu.Unlock()
+	p.once.Do(func() {
+		p.decls = map[int]*ast.FuncDecl{}
+		p.lits = map[[2]int]*ast.FuncLit{}
+		af, fset := s.parse(rel)
+		if af == nil {
+			return
+		}
+		p.AST = af
+		if f := s.files[rel]; f != nil {
+			p.imports = importPaths(f.Imports)
+			if f.Syntax == af {
+				p.Info = f.Info
+			}
+		} else {
+			p.imports = specPaths(af)
+		}
+		ast.Inspect(af, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				p.decls[fset.Position(n.Pos()).Line] = n
+			case *ast.FuncLit:
+				pos := fset.Position(n.Pos())
+				p.lits[[2]int{pos.Line, pos.Column}] = n
+			}
+			return true
+		})
+	})
+	return p
+}
+
+// parse returns the reader's tree of rel if its FileNode has one, else parses
+// its lines; nil if there are none or they do not parse.
+func (s *Syntax) parse(rel string) (*ast.File, *token.FileSet) {
+	var lines []string
+	if f := s.files[rel]; f != nil {
+		if f.Syntax != nil && f.Fset != nil {
+			return f.Syntax, f.Fset
+		}
+		lines = f.Lines
+	} else if s.sources != nil {
+		lines = s.sources(rel)
+	}
+	if lines == nil {
+		return nil, nil
+	}
+	fset := token.NewFileSet()
+	af, err := parser.ParseFile(fset, rel, strings.Join(lines, "\n"), 0)
+	if err != nil {
+		return nil, nil
+	}
+	return af, fset
+}
+
+// FuncNode returns the *ast.FuncDecl or *ast.FuncLit fn was extracted from,
+// or nil if it cannot be found.
+func (s *Syntax) FuncNode(f *core.FileNode, fn *core.FunctionNode) ast.Node {
+	return s.File(f.RelPath).Func(fn)
+}
+
+// Func locates fn's declaration (by start line) or literal (by line and column).
+func (p *SyntaxFile) Func(fn *core.FunctionNode) ast.Node {
+	if fn.Parent != "" {
+		if lit := p.lits[[2]int{fn.StartLine, fn.StartCol}]; lit != nil {
+			return lit
+		}
+		return nil
+	}
+	if d := p.decls[fn.StartLine]; d != nil && d.Name.Name == fn.Name {
+		return d
+	}
+	return nil
+}
+
+// Imports maps the package names the file's code uses for its imports to the
+// import paths. Files in the batch take them from core.FileNode.Imports.
+func (p *SyntaxFile) Imports() map[string]string {
+	return p.imports
+}
+
+func importPaths(imps []model.Import) map[string]string {
+	out := make(map[string]string, len(imps))
+	for _, imp := range imps {
+		if imp.Name != "." {
+			out[imp.Name] = imp.Path
+		}
+	}
+	return out
+}
+
+// specPaths reads the imports of a file outside the batch, which has no
+// FileNode; an unrenamed import gets the name the extractor assumes for it.
+func specPaths(f *ast.File) map[string]string {
+	out := map[string]string{}
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := utils.AssumedPackageName(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			out[name] = p
+		}
+	}
+	return out
+}
@@ -0,0 +1,237 @@
+package testlinks
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	ncg "github.com/vd09-projects/techlead-llm-go-data-creater/internal/callgraph"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+)
+
+// Test entry point kinds (model.TestLink.Kind), in output order.
+const (
+	KindTest      = "test"
+	KindBenchmark = "benchmark"
+	KindFuzz      = "fuzz"
+	KindExample   = "example"
+)
+
+const (
+	viaCall = "call"
+	viaName = "name"
+
+	defaultMaxLinks = 5
+	defaultMaxDepth = 3
+	maxCallers      = 50
+)
+
+var entryPrefixes = []struct{ prefix, kind string }{
+	{"Test", KindTest},
+	{"Benchmark", KindBenchmark},
+	{"Fuzz", KindFuzz},
+	{"Example", KindExample},
+}
+
+type Config struct {
+	RepoRoot string
+	MaxLinks int // per function
+	MaxDepth int // test-file helpers walked between an entry point and the function
+}
+
+func (c Config) withDefaults() Config {
+	out := c
+	if out.MaxLinks <= 0 {
+		out.MaxLinks = defaultMaxLinks
+	}
+	if out.MaxDepth <= 0 {
+		out.MaxDepth = defaultMaxDepth
+	}
+	return out
+}
+
+// Enricher links production functions to the tests, benchmarks, fuzz targets
+// and examples that exercise them: through callers found in _test.go files,
+// and through naming conventions (TestFoo, ExampleT_Method, ...).
+type Enricher struct {
+	cfg      Config
+	computer ncg.Computer
+	workers  int
+}
+
+// New takes the callgraph computer to share with the callgraph enricher; nil
+// restricts linking to naming conventions.
+func New(cfg Config, computer ncg.Computer) *Enricher {
+	return &Enricher{cfg: cfg.withDefaults(), computer: computer}
+}
+
+// WithWorkers sets how many functions are linked concurrently (<= 0 = one per CPU).
+func (e *Enricher) WithWorkers(n int) *Enricher {
+	e.workers = n
+	return e
+}
+
+func (e *Enricher) Kind() core.AspectKind { return core.AspectTests }
+
+// entry is a test entry point and what its name says it exercises.
+type entry struct {
+	link   model.TestLink
+	typ    string // "T" in TestT_Method
+	method string // "Method" in TestT_Method
+	fn     string // "Foo" in TestFoo / TestFoo_case
+}
+
+func (e *Enricher) Enrich(ctx context.Context, repo *core.RepoNode) error {
+	if repo == nil {
+		return nil
+	}
+	if e.computer != nil {
+		if err := e.computer.Init(e.cfg.RepoRoot); err != nil {
+			e.computer = nil // soft-fail: naming conventions only
+		}
+	}
+
+	byDir := map[string][]entry{}
+	for _, f := range repo.Files {
+		if f == nil || !isTestPath(f.RelPath) {
+			continue
+		}
+		for _, fn := range f.Functions {
+			if fn.Recv != "" || fn.Parent != "" {
+				continue
+			}
+			if en, ok := parseEntry(fn.Name, f.RelPath); ok {
+				byDir[path.Dir(f.RelPath)] = append(byDir[path.Dir(f.RelPath)], en)
+			}
+		}
+	}
+
+	return enrichers.ForEachFunction(ctx, repo, e.workers, func(f *core.FileNode, fn *core.FunctionNode) error {
+		if isTestPath(f.RelPath) || fn.Parent != "" {
+			return nil
+		}
+		links := map[string]model.TestLink{}
+		for _, l := range e.callLinks(f.RelPath, fn.Symbol()) {
+			links[l.Path+"|"+l.Symbol] = l
+		}
+		for _, en := range byDir[path.Dir(f.RelPath)] {
+			if _, ok := links[en.link.Path+"|"+en.link.Symbol]; !ok && en.names(fn) {
+				links[en.link.Path+"|"+en.link.Symbol] = en.link
+			}
+		}
+		if len(links) > 0 {
+			fn.Aspects[core.AspectTests] = e.order(links)
+		}
+		return nil
+	})
+}
+
+// callLinks walks callers upwards through _test.go files until it reaches entry points.
+func (e *Enricher) callLinks(rel, sym string) []model.TestLink {
+	if e.computer == nil {
+		return nil
+	}
+	type target struct{ path, sym string }
+	var out []model.TestLink
+	seen := map[target]bool{{rel, sym}: true}
+	frontier := []target{{rel, sym}}
+	for depth := 0; depth < e.cfg.MaxDepth && len(frontier) > 0; depth++ {
+		var next []target
+		for _, t := range frontier {
+			callers, err := e.computer.GetCallers(t.path, t.sym, maxCallers)
+			if err != nil {
+				continue
+			}
+			for _, c := range callers {
+				if !isTestPath(c.Path) {
+					continue
+				}
+				recv, name := ncg.ParseInputSymbol(c.Symbol)
+				// "TestFoo$1" is a t.Run closure inside TestFoo
+				base, _, _ := strings.Cut(name, "$")
+				if en, ok := parseEntry(base, c.Path); ok && recv == "" {
+					en.link.Via = viaCall
+					out = append(out, en.link)
+					continue
+				}
+				if tc := (target{c.Path, c.Symbol}); !seen[tc] {
+					seen[tc] = true
+					next = append(next, tc)
+				}
+			}
+		}
+		frontier = next
+	}
+	return out
+}
+
+func (e *Enricher) order(links map[string]model.TestLink) []model.TestLink {
+	rank := map[string]int{}
+	for i, p := range entryPrefixes {
+		rank[p.kind] = i
+	}
+	out := make([]model.TestLink, 0, len(links))
+	for _, l := range links {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return rank[out[i].Kind] < rank[out[j].Kind]
+		}
+		if out[i].Via != out[j].Via {
+			return out[i].Via == viaCall
+		}
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out[:utils.Min(len(out), e.cfg.MaxLinks)]
+}
+
+// parseEntry recognizes go test entry points: the prefix must be followed by
+// nothing or a non-lowercase rune ("Testing" is not a test). Example_suffix
+// and Test_foo strip the leading "_" before naming their target.
+func parseEntry(name, rel string) (entry, bool) {
+	for _, p := range entryPrefixes {
+		rest, ok := strings.CutPrefix(name, p.prefix)
+		if !ok {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(rest); rest != "" && unicode.IsLower(r) {
+			return entry{}, false
+		}
+		en := entry{link: model.TestLink{Symbol: name, Path: rel, Kind: p.kind, Via: viaName}}
+		parts := strings.Split(strings.TrimPrefix(rest, "_"), "_")
+		en.fn = parts[0]
+		if len(parts) > 1 {
+			en.typ, en.method = parts[0], parts[1]
+		}
+		return en, true
+	}
+	return entry{}, false
+}
+
+// names reports whether the entry's name targets fn (first letter case-insensitive,
+// so TestParse covers parse).
+func (en entry) names(fn *core.FunctionNode) bool {
+	if fn.Recv == "" {
+		return en.fn != "" && sameName(en.fn, fn.Name)
+	}
+	typ, _, _ := strings.Cut(utils.RecvBaseType(fn.Recv), "[") // "T[K]" -> "T"
+	return en.method != "" && sameName(en.typ, typ) && sameName(en.method, fn.Name)
+}
+
+func sameName(a, b string) bool {
+	ra, na := utf8.DecodeRuneInString(a)
+	rb, nb := utf8.DecodeRuneInString(b)
+	return unicode.ToLower(ra) == unicode.ToLower(rb) && a[na:] == b[nb:]
+}
+
+func isTestPath(rel string) bool { return strings.HasSuffix(rel, "_test.go") }
@@ -0,0 +1,277 @@
+// Package evolution mines before/after versions of functions from git history.
+package evolution
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/extractor"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/gitutil"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// Change kinds (Record.Change).
+const (
+	Modified = "modified"
+	Added    = "added"
+	Deleted  = "deleted"
+)
+
+// Record is one function as a commit changed it.
+type Record struct {
+	Repo      string   `json:"repo"`
+	Commit    string   `json:"commit"`
+	Parent    string   `json:"parent,omitempty"` // "" for a root commit
+	Author    string   `json:"author"`
+	Date      string   `json:"date"`
+	Message   string   `json:"message"`
+	Path      string   `json:"path"`
+	OldPath   string   `json:"old_path,omitempty"` // set when the commit renamed the file
+	Kind      string   `json:"kind"`               // function | method | closure
+	Symbol    string   `json:"symbol"`
+	Change    string   `json:"change"` // modified | added | deleted
+	Before    *Version `json:"before,omitempty"`
+	After     *Version `json:"after,omitempty"`
+	Diff      string   `json:"diff"` // the file's hunks that touch the function
+	TestFile  bool     `json:"test_file,omitempty"`
+	FileClass []string `json:"file_class,omitempty"`
+}
+
+// Version is the function on one side of the commit.
+type Version struct {
+	Signature   string `json:"signature"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	Code        string `json:"code"`
+	Doc         string `json:"doc,omitempty"`
+	InvalidCode bool   `json:"invalid_code,omitempty"`
+}
+
+// Miner walks a repo's history and pairs each changed function's versions.
+// Functions are matched by the symbol scanrepo gives them (core.ToRecords),
+// so a record here joins with the scan of either commit.
+type Miner struct {
+	RepoRoot   string
+	RepoName   string
+	Extractor  *extractor.ASTExtractor
+	ExcludeREs []*regexp.Regexp
+	Changes    map[string]bool // kinds to emit (default: modified only)
+	Context    int             // diff context lines
+}
+
+func New(repoRoot string, ex *extractor.ASTExtractor) *Miner {
+	return &Miner{
+		RepoRoot:  repoRoot,
+		RepoName:  gitutil.InferRepoName(repoRoot),
+		Extractor: ex,
+		Changes:   map[string]bool{Modified: true},
+		Context:   3,
+	}
+}
+
+// WithExclude skips files whose path (either side of a rename) matches any
+// of the comma-separated regexes.
+func (m *Miner) WithExclude(csv string) *Miner {
+	m.ExcludeREs = nil
+	for _, p := range strings.Split(csv, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			m.ExcludeREs = append(m.ExcludeREs, regexp.MustCompile(p))
+		}
+	}
+	return m
+}
+
+// WithChanges sets which change kinds are emitted (modified | added | deleted).
+func (m *Miner) WithChanges(kinds ...string) *Miner {
+	m.Changes = map[string]bool{}
+	for _, k := range kinds {
+		m.Changes[k] = true
+	}
+	return m
+}
+
+// WithContext sets the context lines around each diff hunk.
+func (m *Miner) WithContext(n int) *Miner {
+	m.Context = n
+	return m
+}
+
+// Run mines the commits of rev (see gitutil.Log), newest first, and hands
+// each commit's records to emit. A file that does not parse on either side is
+// skipped; git failures and emit errors stop the run.
+func (m *Miner) Run(ctx context.Context, rev string, maxCommits int, emit func([]Record) error) error {
+	commits, err := gitutil.Log(m.RepoRoot, rev, maxCommits)
+	if err != nil {
+		return err
+	}
+	for _, c := range commits {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		recs, err := m.Commit(ctx, c)
+		if err != nil {
+			return err
+		}
+		if len(recs) > 0 {
+			if err := emit(recs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Commit returns the records of one commit, in file then line order.
+func (m *Miner) Commit(ctx context.Context, c gitutil.Commit) ([]Record, error) {
+	files, err := gitutil.ChangedFiles(m.RepoRoot, c.Hash)
+	if err != nil {
+		return nil, err
+	}
+	var out []Record
+	for _, fc := range files {
+		if m.excluded(fc.OldPath) || m.excluded(fc.NewPath) {
+			continue
+		}
+		var before, after []model.Record
+		if fc.OldPath != "" && c.Parent != "" {
+			if before, err = m.functionsAt(ctx, c.Parent, fc.OldPath); err != nil {
+				return nil, err
+			}
+		}
+		if fc.NewPath != "" {
+			if after, err = m.functionsAt(ctx, c.Hash, fc.NewPath); err != nil {
+				return nil, err
+			}
+		}
+		if len(before) == 0 && len(after) == 0 {
+			continue
+		}
+		hunks, err := gitutil.FileHunks(m.RepoRoot, c.Hash, fc, m.Context)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m.pair(c, fc, before, after, hunks)...)
+	}
+	return out, nil
+}
+
+// pair matches before and after by symbol and keeps the changed functions.
+func (m *Miner) pair(c gitutil.Commit, fc gitutil.FileChange, before, after []model.Record, hunks []gitutil.Hunk) []Record {
+	old := make(map[string]*model.Record, len(before))
+	for i := range before {
+		old[before[i].Symbol] = &before[i]
+	}
+	rec := func(change string, b, a *model.Record) Record {
+		r := Record{
+			Repo: m.RepoName, Commit: c.Hash, Parent: c.Parent,
+			Author: c.Author, Date: c.Date, Message: c.Message,
+			Path: fc.NewPath, Change: change,
+		}
+		if fc.OldPath != fc.NewPath {
+			r.OldPath = fc.OldPath
+		}
+		if r.Path == "" {
+			r.Path = fc.OldPath
+		}
+		var diff strings.Builder
+		for _, h := range hunks {
+			if (b != nil && overlaps(h.OldSpan, b)) || (a != nil && overlaps(h.NewSpan, a)) {
+				diff.WriteString(h.Text)
+			}
+		}
+		r.Diff = diff.String()
+		for _, v := range []*model.Record{a, b} {
+			if v != nil {
+				r.Kind, r.Symbol, r.TestFile, r.FileClass = v.Kind, v.Symbol, v.TestFile, v.FileClass
+				break
+			}
+		}
+		r.Before, r.After = version(b), version(a)
+		return r
+	}
+
+	var out []Record
+	for i := range after {
+		a := &after[i]
+		b, ok := old[a.Symbol]
+		delete(old, a.Symbol)
+		switch {
+		case !ok:
+			if m.Changes[Added] {
+				out = append(out, rec(Added, nil, a))
+			}
+		case b.Code != a.Code || b.Signature != a.Signature || b.Doc != a.Doc:
+			if m.Changes[Modified] {
+				out = append(out, rec(Modified, b, a))
+			}
+		}
+	}
+	if m.Changes[Deleted] {
+		for i := range before {
+			if b := &before[i]; old[b.Symbol] == b {
+				out = append(out, rec(Deleted, b, nil))
+			}
+		}
+	}
+	return out
+}
+
+// functionsAt extracts path's functions at rev as scanrepo would.
+func (m *Miner) functionsAt(ctx context.Context, rev, path string) ([]model.Record, error) {
+	src, err := gitutil.Show(m.RepoRoot, rev, path)
+	if err != nil {
+		return nil, err
+	}
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil
+	}
+	unit := scanner.FileUnit{
+		Filename: filepath.Join(m.RepoRoot, filepath.FromSlash(path)),
+		RelPath:  path, File: f, Fset: fset, Src: src,
+	}
+	files, err := m.Extractor.Extract(ctx, []scanner.FileUnit{unit})
+	if err != nil {
+		return nil, err
+	}
+	recs := core.ToRecords(&core.RepoNode{Root: m.RepoRoot, Files: files}, m.RepoName, rev, "go")
+	out := recs[:0]
+	for _, r := range recs {
+		if r.Kind != model.KindType {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *Miner) excluded(path string) bool {
+	for _, re := range m.ExcludeREs {
+		if path != "" && re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(span func() (int, int), r *model.Record) bool {
+	start, end := span()
+	return start <= r.EndLine && r.StartLine <= end
+}
+
+func version(r *model.Record) *Version {
+	if r == nil {
+		return nil
+	}
+	return &Version{
+		Signature: r.Signature, StartLine: r.StartLine, EndLine: r.EndLine,
+		Code: r.Code, Doc: r.Doc, InvalidCode: r.InvalidCode,
+	}
+}
@@ -0,0 +1,109 @@
+package extractor
+
+import (
+	"go/ast"
+	"sort"
+	"strconv"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// extractClosures emits every *ast.FuncLit inside fd as its own node.
+// Names follow SSA's anonymous function scheme so callgraph edges line up:
+// the n-th literal directly inside Foo is "Foo$n", literals nested in it are "Foo$n$m".
+// Numbering counts every literal; line filters are applied afterwards.
+func (e *ASTExtractor) extractClosures(u scanner.FileUnit, fd *ast.FuncDecl, recv string) (out []*core.FunctionNode) {
+	if fd.Body == nil {
+		return nil
+	}
+	parentSym := fd.Name.Name
+	if recv != "" {
+		parentSym = recv + "." + parentSym
+	}
+
+	var walk func(body ast.Node, parentName, parentSym string)
+	walk = func(body ast.Node, parentName, parentSym string) {
+		n := 0
+		ast.Inspect(body, func(node ast.Node) bool {
+			lit, ok := node.(*ast.FuncLit)
+			if !ok || lit == body {
+				return true
+			}
+			n++
+			name := parentName + "$" + strconv.Itoa(n)
+			if fn := e.closureNode(u, fd, lit, name, recv, parentSym); fn != nil {
+				out = append(out, fn)
+			}
+			sym := name
+			if recv != "" {
+				sym = recv + "." + name
+			}
+			walk(lit, name, sym)
+			return false // nested literals are numbered relative to lit
+		})
+	}
+	walk(fd.Body, fd.Name.Name, parentSym)
+	return out
+}
+
+func (e *ASTExtractor) closureNode(u scanner.FileUnit, fd *ast.FuncDecl, lit *ast.FuncLit, name, recv, parentSym string) *core.FunctionNode {
+	code, elided := e.functionCode(u, lit, lit.Body)
+	trimmed, lines, valid := e.trim(code)
+	if lineCount(trimmed) < e.MinFuncLines || (!valid && e.DropInvalid) {
+		return nil
+	}
+	elided = keptElisions(trimmed, elided)
+	start := u.Fset.PositionFor(lit.Pos(), true)
+	typedSig, typeRefs := typedSignature(u, lit)
+	return &core.FunctionNode{
+		Name:          name,
+		Recv:          recv,
+		Signature:     exprText(u, lit.Type),
+		TypedSig:      typedSig,
+		TypeRefs:      typeRefs,
+		StartLine:     start.Line,
+		EndLine:       u.Fset.PositionFor(lit.End(), true).Line,
+		TrimmedLength: lines,
+		Code:          ensureTrailingNL(trimmed),
+		Tokens:        e.countTokens(trimmed),
+		Comments:      e.listComments(u, lit.Pos(), lit.End()),
+		InvalidCode:   !valid,
+		Elided:        elided,
+		IsTestFile:    testFileRe.MatchString(u.RelPath),
+		Aspects:       make(map[core.AspectKind]any),
+		Parent:        parentSym,
+		Captures:      capturedVars(fd, lit),
+		StartCol:      start.Column,
+	}
+}
+
+// capturedVars lists variables referenced in lit but declared in the enclosing
+// function outside of it (params, receiver, locals). Package-level names are not captures.
+func capturedVars(fd *ast.FuncDecl, lit *ast.FuncLit) []string {
+	seen := map[string]bool{}
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id.Obj == nil || id.Obj.Kind != ast.Var {
+			return true
+		}
+		decl := id.Obj.Pos()
+		if !decl.IsValid() || decl < fd.Pos() || decl >= fd.End() {
+			return true
+		}
+		if decl >= lit.Pos() && decl < lit.End() {
+			return true
+		}
+		seen[id.Name] = true
+		return true
+	})
+	if len(seen) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(seen))
+	for k := range seen {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
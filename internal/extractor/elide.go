@@ -0,0 +1,203 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// TrimMode selects how over-long code is shortened.
+type TrimMode string
+
+const (
+	TrimHead       TrimMode = "head"       // keep the first N lines, close open blocks (default)
+	TrimStructural TrimMode = "structural" // elide inner block / case bodies, keep the skeleton
+)
+
+func ParseTrimMode(s string) (TrimMode, error) {
+	switch m := TrimMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case TrimHead, TrimStructural:
+		return m, nil
+	case "":
+		return TrimHead, nil
+	default:
+		return "", fmt.Errorf("unknown trim mode %q (want head|structural)", s)
+	}
+}
+
+// elidedMarkerPrefix starts every placeholder; removeComments keeps these.
+const elidedMarkerPrefix = "// ... elided"
+
+// elision is a candidate run of whole source lines [start, end] inside a nested block.
+type elision struct {
+	start, end int
+	depth      int
+}
+
+// elideBlocks shortens the code of node (a FuncDecl or FuncLit spanning source
+// lines) by replacing nested block and case bodies with a one-line placeholder,
+// deepest first, until it fits the line and token limits after trimming. The
+// top-level body and any trailing return of an elided block are kept, so the
+// control skeleton and exit paths stay visible. It returns the rewritten code
+// and the elided source line spans.
+func (e *ASTExtractor) elideBlocks(u scanner.FileUnit, node ast.Node, body *ast.BlockStmt) (string, []model.Span) {
+	code := sliceByPos(u.Src, u.Fset, node.Pos(), node.End())
+	if body == nil || e.fits(code) {
+		return code, nil
+	}
+
+	src := strings.Split(u.Src, "\n")
+	first := u.Fset.PositionFor(node.Pos(), true).Line
+	cands := elisionCandidates(u, src, body)
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].depth != cands[j].depth {
+			return cands[i].depth > cands[j].depth
+		}
+		return cands[i].end-cands[i].start > cands[j].end-cands[j].start
+	})
+
+	var chosen []elision
+	for _, c := range cands {
+		if covered(chosen, c) {
+			continue
+		}
+		chosen = append(dropInside(chosen, c), c)
+		code = renderElided(strings.Split(sliceByPos(u.Src, u.Fset, node.Pos(), node.End()), "\n"), src, first, chosen)
+		if e.fits(code) {
+			break
+		}
+	}
+
+	sort.Slice(chosen, func(i, j int) bool { return chosen[i].start < chosen[j].start })
+	spans := make([]model.Span, 0, len(chosen))
+	for _, c := range chosen {
+		spans = append(spans, model.Span{StartLine: c.start, EndLine: c.end})
+	}
+	return code, spans
+}
+
+// fits reports whether code, after comment handling, is within the line and token limits.
+func (e *ASTExtractor) fits(code string) bool {
+	out, _, _ := trimFunctionCode(code, 0, e.Comments == CommentsKeep)
+	if e.MaxFuncLines > 0 && lineCount(strings.TrimRight(out, "\n")) > e.MaxFuncLines {
+		return false
+	}
+	return !e.tokenBudget() || e.Tokenizer.Count(out) <= e.MaxTokens
+}
+
+// elisionCandidates collects the statement runs of every block nested in body
+// (if/else/for/range/func literal/bare blocks) and of every case/comm clause.
+func elisionCandidates(u scanner.FileUnit, src []string, body *ast.BlockStmt) []elision {
+	var out []elision
+	var visit func(n ast.Node, depth int)
+	visit = func(n ast.Node, depth int) {
+		ast.Inspect(n, func(c ast.Node) bool {
+			if c == n {
+				return true
+			}
+			var stmts []ast.Stmt
+			switch t := c.(type) {
+			case *ast.BlockStmt:
+				stmts = t.List
+			case *ast.CaseClause:
+				stmts = t.Body
+			case *ast.CommClause:
+				stmts = t.Body
+			case *ast.SwitchStmt:
+				visit(t.Body, depth) // the body is a clause list; the clauses are the candidates
+				return false
+			case *ast.TypeSwitchStmt:
+				visit(t.Body, depth)
+				return false
+			case *ast.SelectStmt:
+				visit(t.Body, depth)
+				return false
+			default:
+				return true
+			}
+			if el, ok := stmtRun(u, src, stmts); ok {
+				el.depth = depth
+				out = append(out, el)
+			}
+			visit(c, depth+1)
+			return false
+		})
+	}
+	visit(body, 1)
+	return out
+}
+
+// stmtRun returns the whole-line span of stmts minus a trailing return, if those
+// lines hold nothing else (no "{" or "}" of the enclosing block, no other code).
+func stmtRun(u scanner.FileUnit, src []string, stmts []ast.Stmt) (elision, bool) {
+	if n := len(stmts); n > 0 {
+		if _, ok := stmts[n-1].(*ast.ReturnStmt); ok {
+			stmts = stmts[:n-1]
+		}
+	}
+	if len(stmts) == 0 {
+		return elision{}, false
+	}
+	from := u.Fset.PositionFor(stmts[0].Pos(), true)
+	to := u.Fset.PositionFor(stmts[len(stmts)-1].End(), true)
+	if to.Line-from.Line < 1 || from.Line < 1 || to.Line > len(src) {
+		return elision{}, false // a single line saves nothing
+	}
+	if from.Column-1 > len(src[from.Line-1]) || to.Column-1 > len(src[to.Line-1]) {
+		return elision{}, false
+	}
+	before := src[from.Line-1][:from.Column-1]
+	after := src[to.Line-1][to.Column-1:]
+	if strings.TrimSpace(before) != "" {
+		return elision{}, false
+	}
+	if a := strings.TrimSpace(after); a != "" && !strings.HasPrefix(a, "//") {
+		return elision{}, false
+	}
+	return elision{start: from.Line, end: to.Line}, true
+}
+
+func covered(chosen []elision, c elision) bool {
+	for _, o := range chosen {
+		if o.start <= c.start && c.end <= o.end {
+			return true
+		}
+	}
+	return false
+}
+
+func dropInside(chosen []elision, c elision) []elision {
+	out := chosen[:0]
+	for _, o := range chosen {
+		if c.start <= o.start && o.end <= c.end {
+			continue
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// renderElided replaces each chosen span in code (whose first line is source line first).
+func renderElided(code, src []string, first int, chosen []elision) string {
+	skip := map[int]elision{}
+	for _, c := range chosen {
+		skip[c.start] = c
+	}
+	var out []string
+	for i := 0; i < len(code); i++ {
+		line := first + i
+		c, ok := skip[line]
+		if !ok {
+			out = append(out, code[i])
+			continue
+		}
+		n := c.end - c.start + 1
+		out = append(out, fmt.Sprintf("%s%s %d lines ...", indentOf(src[line-1]), elidedMarkerPrefix, n))
+		i += n - 1
+	}
+	return strings.Join(out, "\n")
+}
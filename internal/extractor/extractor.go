@@ -1,60 +1,181 @@
 package extractor
 
 import (
+	"context"
+	"fmt"
 	"go/ast"
 	"go/token"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/classify"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
 )
 
-var genCodeRe = regexp.MustCompile(`(?i)^\s*//\s*Code generated`)
 var testFileRe = regexp.MustCompile(`_test\.go$`)
 
 type Extractor interface {
-	Extract(units []scanner.FileUnit) []*core.FileNode
+	Extract(ctx context.Context, units []scanner.FileUnit) ([]*core.FileNode, error)
+}
+
+// CommentMode controls what happens to comments inside emitted code.
+type CommentMode string
+
+const (
+	CommentsStrip CommentMode = "strip" // remove from code (default)
+	CommentsKeep  CommentMode = "keep"  // leave in code as written
+	CommentsList  CommentMode = "list"  // remove from code, list on the record
+)
+
+func ParseCommentMode(s string) (CommentMode, error) {
+	switch m := CommentMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case CommentsStrip, CommentsKeep, CommentsList:
+		return m, nil
+	case "":
+		return CommentsStrip, nil
+	default:
+		return "", fmt.Errorf("unknown comment mode %q (want strip|keep|list)", s)
+	}
 }
 
 type ASTExtractor struct {
 	MaxFuncLines int
 	MinFuncLines int
+	IncludeTypes bool
+
+	IncludeClosures bool
+	Comments        CommentMode
+	DropInvalid     bool
+	Trim            TrimMode
+
+	Tokenizer tokenizer.Counter // optional; enables token counts and MaxTokens
+	MaxTokens int               // cap on code tokens (0 = none)
+
+	FilePolicies classify.Policies // what to do with generated, mock, test, ... files
+	Workers      int               // files extracted concurrently (<= 0 = one per CPU)
 }
 
 func NewASTExtractor(minFuncLines, maxFuncLines int) *ASTExtractor {
 	return &ASTExtractor{
 		MaxFuncLines: maxFuncLines,
 		MinFuncLines: minFuncLines,
+		Comments:     CommentsStrip,
+		Trim:         TrimHead,
+		FilePolicies: classify.DefaultPolicies(),
 	}
 }
 
-func (e *ASTExtractor) Extract(units []scanner.FileUnit) []*core.FileNode {
+// WithTypes enables type declaration records (struct, interface, named, alias).
+func (e *ASTExtractor) WithTypes(on bool) *ASTExtractor {
+	e.IncludeTypes = on
+	return e
+}
+
+// WithClosures enables function literal records linked to their enclosing function.
+func (e *ASTExtractor) WithClosures(on bool) *ASTExtractor {
+	e.IncludeClosures = on
+	return e
+}
+
+// WithComments selects how comments inside code are treated; doc comments are always captured.
+func (e *ASTExtractor) WithComments(mode CommentMode) *ASTExtractor {
+	e.Comments = mode
+	return e
+}
+
+// WithDropInvalid skips nodes whose emitted code no longer parses; otherwise they are flagged.
+func (e *ASTExtractor) WithDropInvalid(on bool) *ASTExtractor {
+	e.DropInvalid = on
+	return e
+}
+
+// WithTrimMode selects head-capping or structural elision for over-long functions.
+func (e *ASTExtractor) WithTrimMode(mode TrimMode) *ASTExtractor {
+	e.Trim = mode
+	return e
+}
+
+// WithTokenBudget counts code tokens with tok and, when maxTokens > 0, trims code to fit.
+func (e *ASTExtractor) WithTokenBudget(tok tokenizer.Counter, maxTokens int) *ASTExtractor {
+	e.Tokenizer = tok
+	e.MaxTokens = maxTokens
+	return e
+}
+
+// WithFilePolicies sets the include/exclude/flag policy per file class.
+func (e *ASTExtractor) WithFilePolicies(p classify.Policies) *ASTExtractor {
+	e.FilePolicies = p
+	return e
+}
+
+// WithWorkers sets how many files are extracted concurrently.
+func (e *ASTExtractor) WithWorkers(n int) *ASTExtractor {
+	e.Workers = n
+	return e
+}
+
+// Extract builds one FileNode per unit, in unit order whatever the parallelism.
+func (e *ASTExtractor) Extract(ctx context.Context, units []scanner.FileUnit) ([]*core.FileNode, error) {
+	var methods methodSets
+	if e.IncludeTypes {
+		methods = collectMethodSets(units)
+	}
+
+	nodes := make([]*core.FileNode, len(units))
+	err := utils.ForEach(ctx, len(units), e.Workers, func(i int) error {
+		nodes[i] = e.extractFile(units[i], methods)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	out := make([]*core.FileNode, 0, len(units))
-	for _, fu := range units {
-		fnodes := e.extractFunctions(fu)
-		if len(fnodes) == 0 {
-			continue
+	for _, n := range nodes {
+		if n != nil {
+			out = append(out, n)
 		}
-		lines := strings.Split(fu.Src, "\n")
-		out = append(out, &core.FileNode{
-			RelPath:   fu.RelPath,
-			Lines:     lines,
-			Functions: fnodes,
-		})
 	}
-	return out
+	return out, nil
 }
 
-func (e *ASTExtractor) extractFunctions(u scanner.FileUnit) (out []*core.FunctionNode) {
-	// Skip generated (first 5 lines)
-	headLines := strings.Split(u.Src, "\n")
-	head := strings.Join(headLines[:utils.Min(5, len(headLines))], "\n")
-	if genCodeRe.MatchString(head) {
+// extractFile returns nil for excluded files and files with nothing to emit.
+func (e *ASTExtractor) extractFile(fu scanner.FileUnit, methods methodSets) *core.FileNode {
+	classes := classify.File(fu.RelPath, fu.Src)
+	policy := e.FilePolicies.Resolve(classes)
+	if policy == classify.Exclude {
+		return nil
+	}
+	fnodes := e.extractFunctions(fu)
+	var tnodes []*core.TypeNode
+	if e.IncludeTypes {
+		tnodes = e.extractTypes(fu, methods)
+	}
+	if len(fnodes) == 0 && len(tnodes) == 0 {
 		return nil
 	}
+	lines := strings.Split(fu.Src, "\n")
+	return &core.FileNode{
+		RelPath:   fu.RelPath,
+		Module:    fu.Module,
+		Lines:     lines,
+		Imports:   fileImports(fu),
+		Functions: fnodes,
+		Types:     tnodes,
+		Classes:   classify.Strings(classes),
+		Flagged:   policy == classify.Flag,
+		Syntax:    fu.File,
+		Fset:      fu.Fset,
+		Info:      fu.Info,
+	}
+}
 
+func (e *ASTExtractor) extractFunctions(u scanner.FileUnit) (out []*core.FunctionNode) {
 	ast.Inspect(u.File, func(n ast.Node) bool {
 		fd, ok := n.(*ast.FuncDecl)
 		if !ok || fd.Name == nil {
@@ -68,32 +189,41 @@ func (e *ASTExtractor) extractFunctions(u scanner.FileUnit) (out []*core.Functio
 			recv = "(" + recvType + ")"
 		}
 
-		signEnd := fd.End()
-		if fd.Body != nil {
-			signEnd = fd.Body.Lbrace
+		signature := funcSignature(u, fd)
+		typedSig, typeRefs := typedSignature(u, fd)
+
+		if e.IncludeClosures {
+			out = append(out, e.extractClosures(u, fd, recv)...)
 		}
-		signature := strings.TrimSpace(sliceByPos(u.Src, u.Fset, fd.Pos(), signEnd))
 
 		start := u.Fset.PositionFor(fd.Pos(), true).Line
 		endPos := fd.End()
 		end := u.Fset.PositionFor(endPos, true).Line
-		code := sliceByPos(u.Src, u.Fset, fd.Pos(), fd.End())
+		code, elided := e.functionCode(u, fd, fd.Body)
 
-		trimmed, lines := trimFunctionCode(code, e.MaxFuncLines)
-		if lineCount(trimmed) < e.MinFuncLines {
+		trimmed, lines, valid := e.trim(code)
+		if lineCount(trimmed) < e.MinFuncLines || (!valid && e.DropInvalid) {
 			return true
 		}
+		elided = keptElisions(trimmed, elided)
 
 		out = append(out, &core.FunctionNode{
 			Name:      name,
 			Recv:      recv,      // "(*T)" or "(T)" or ""
 			Signature: signature, // "func ... {"
+			TypedSig:  typedSig,
+			TypeRefs:  typeRefs,
 			StartLine: start,
 			// EndLine:       start + lines - 1,
 			// TrimmedLength: end,
 			EndLine:       end,
 			TrimmedLength: lines,
 			Code:          ensureTrailingNL(trimmed),
+			Tokens:        e.countTokens(trimmed),
+			Doc:           docText(fd.Doc),
+			Comments:      e.listComments(u, fd.Pos(), fd.End()),
+			InvalidCode:   !valid,
+			Elided:        elided,
 			IsTestFile:    testFileRe.MatchString(u.RelPath),
 			Aspects:       make(map[core.AspectKind]any),
 		})
@@ -102,6 +232,84 @@ func (e *ASTExtractor) extractFunctions(u scanner.FileUnit) (out []*core.Functio
 	return out
 }
 
+// funcSignature returns the declaration text up to (not including) the body.
+func funcSignature(u scanner.FileUnit, fd *ast.FuncDecl) string {
+	signEnd := fd.End()
+	if fd.Body != nil {
+		signEnd = fd.Body.Lbrace
+	}
+	return strings.TrimSpace(sliceByPos(u.Src, u.Fset, fd.Pos(), signEnd))
+}
+
+// functionCode returns the source of a function (decl or literal), structurally
+// elided first when that trim mode is selected.
+func (e *ASTExtractor) functionCode(u scanner.FileUnit, node ast.Node, body *ast.BlockStmt) (string, []model.Span) {
+	if e.Trim == TrimStructural {
+		return e.elideBlocks(u, node, body)
+	}
+	return sliceByPos(u.Src, u.Fset, node.Pos(), node.End()), nil
+}
+
+// keptElisions drops spans whose placeholder was cut away by the head cap
+// (the cap keeps a prefix, so the surviving placeholders are the first ones).
+func keptElisions(code string, spans []model.Span) []model.Span {
+	if n := strings.Count(code, elidedMarkerPrefix); n < len(spans) {
+		return spans[:n]
+	}
+	return spans
+}
+
+// trim applies the line cap, then tightens it until the code fits the token budget.
+func (e *ASTExtractor) trim(code string) (string, int, bool) {
+	keep := e.Comments == CommentsKeep
+	out, lines, ok := trimFunctionCode(code, e.MaxFuncLines, keep)
+	if !e.tokenBudget() || e.Tokenizer.Count(out) <= e.MaxTokens {
+		return out, lines, ok
+	}
+	// smallest over-budget cap; the one below it is the largest that fits (at least one line is kept)
+	n := sort.Search(lines, func(i int) bool {
+		c, _, _ := trimFunctionCode(code, i+1, keep)
+		return e.Tokenizer.Count(c) > e.MaxTokens
+	})
+	return trimFunctionCode(code, max(n, 1), keep)
+}
+
+func (e *ASTExtractor) tokenBudget() bool {
+	return e.Tokenizer != nil && e.MaxTokens > 0
+}
+
+func (e *ASTExtractor) countTokens(code string) int {
+	if e.Tokenizer == nil {
+		return 0
+	}
+	return e.Tokenizer.Count(code)
+}
+
+// listComments returns comment groups inside [from, to) when in list mode.
+// Doc comments precede the declaration position and are therefore never included.
+func (e *ASTExtractor) listComments(u scanner.FileUnit, from, to token.Pos) []model.Comment {
+	if e.Comments != CommentsList || u.File == nil {
+		return nil
+	}
+	var out []model.Comment
+	for _, g := range u.File.Comments {
+		if g.Pos() < from || g.End() > to {
+			continue
+		}
+		if txt := strings.TrimSpace(g.Text()); txt != "" {
+			out = append(out, model.Comment{
+				Line: u.Fset.PositionFor(g.Pos(), true).Line,
+				Text: txt,
+			})
+		}
+	}
+	return out
+}
+
+func docText(g *ast.CommentGroup) string {
+	return strings.TrimSpace(g.Text())
+}
+
 func sliceByPos(src string, fset *token.FileSet, start, end token.Pos) string {
 	p0 := fset.PositionFor(start, true).Offset
 	p1 := fset.PositionFor(end, true).Offset
@@ -0,0 +1,50 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+)
+
+// fileImports lists the imports of a file, blank imports excluded. Without
+// type information the package name of an unrenamed import is guessed from
+// its path, the way goimports does.
+func fileImports(u scanner.FileUnit) []model.Import {
+	var out []model.Import
+	for _, spec := range u.File.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		imp := model.Import{Path: p, Class: importClass(p, u.Module)}
+		switch {
+		case spec.Name != nil:
+			imp.Name, imp.Alias = spec.Name.Name, true
+		case u.Info != nil && u.Info.PkgNameOf(spec) != nil:
+			imp.Name = u.Info.PkgNameOf(spec).Name()
+		default:
+			imp.Name = utils.AssumedPackageName(p)
+		}
+		if imp.Name == "_" {
+			continue
+		}
+		out = append(out, imp)
+	}
+	return out
+}
+
+// importClass tells standard library (no dot in the first path element),
+// same-module and third-party imports apart.
+func importClass(importPath, module string) string {
+	switch {
+	case module != "" && (importPath == module || strings.HasPrefix(importPath, module+"/")):
+		return model.ImportModule
+	case !strings.Contains(strings.SplitN(importPath, "/", 2)[0], "."):
+		return model.ImportStdlib
+	default:
+		return model.ImportThirdParty
+	}
+}
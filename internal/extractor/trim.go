@@ -1,92 +1,331 @@
 package extractor
 
 import (
-	"regexp"
+	"go/ast"
+	"go/parser"
+	goscanner "go/scanner"
+	"go/token"
+	"slices"
 	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
 )
 
-// very simple comment removers (regex-based; will NOT respect strings)
-var slCommentRe = regexp.MustCompile(`(?m)//[^\n]*`)
-var mlCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/`)
+const trimmedMarker = "// ... trimmed ..."
+
+// trimFunctionCode removes comments (unless keepComments), then line-caps.
+// Both steps work on go/scanner tokens, so string, raw string and rune literals
+// are never touched. The cap only cuts at a statement boundary inside the body
+// and closes whatever brackets are still open, so the output keeps parsing.
+// ok reports whether the result is syntactically valid Go.
+func trimFunctionCode(full string, maxLines int, keepComments bool) (code string, lines int, ok bool) {
+	if !keepComments {
+		full = removeComments(full)
+	}
+	full = strings.TrimRight(scanner.NormalizeNewlines(full), "\n")
+
+	out := capAtStatement(full, maxLines)
+	return ensureTrailingNL(out), lineCount(out), parses(out)
+}
 
-// trimFunctionCodeSimple removes comments, then line-caps.
-// If a "{" exists, it preserves the signature (up to and including "{")
-// and applies the line-cap to the body portion.
-func trimFunctionCode(full string, maxLines int) (string, int) {
-	full = normalizeNewlines(full)
+// removeComments drops every comment token except elision placeholders. A comment containing a newline is
+// replaced by one (it acted as a newline for semicolon insertion), others by a
+// space. Blanks after a removed comment are skipped when the output already
+// ends in one (or starts a line). Lines left blank by the removal are dropped;
+// original blank lines stay. Blanks left before a removed comment are trimmed
+// by the caller.
+func removeComments(s string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(s))
+	var sc goscanner.Scanner
+	sc.Init(file, []byte(s), nil, goscanner.ScanComments)
 
-	// find opening brace to split signature/body (optional nicety)
-	openIdx := strings.Index(full, "{")
-	if openIdx == -1 {
-		// no body; just remove comments & cap
-		clean := removeComments(full)
-		return capByLines(clean, maxLines)
+	var b strings.Builder
+	touched := map[int]bool{} // output line index -> a comment was removed there
+	outLine, last := 0, 0
+	write := func(chunk string) {
+		b.WriteString(chunk)
+		outLine += strings.Count(chunk, "\n")
 	}
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT || strings.HasPrefix(lit, elidedMarkerPrefix) {
+			continue
+		}
+		off := file.Offset(pos)
+		write(s[last:off])
+		touched[outLine] = true
+		if strings.Contains(lit, "\n") {
+			write("\n")
+			touched[outLine] = true
+		} else if !strings.HasPrefix(lit, "//") && needsSpace(s, off, off+len(lit)) {
+			write(" ")
+		}
+		last = off + len(lit)
+		if out := b.String(); out == "" || strings.ContainsRune(" \t\n", rune(out[len(out)-1])) {
+			for last < len(s) && (s[last] == ' ' || s[last] == '\t') {
+				last++ // "/* x */ y" at a line start, or "a /* x */ b": keep one blank at most
+			}
+		}
+	}
+	write(s[last:])
+
+	src := strings.Split(b.String(), "\n")
+	kept := src[:0]
+	for i, l := range src {
+		if touched[i] && strings.TrimSpace(l) == "" {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// needsSpace reports whether removing s[from:to] would glue two tokens together.
+func needsSpace(s string, from, to int) bool {
+	isWS := func(c byte) bool { return c == ' ' || c == '\t' || c == '\n' }
+	return from > 0 && to < len(s) && !isWS(s[from-1]) && !isWS(s[to])
+}
+
+// opener is an unclosed LBRACE/LPAREN/LBRACK and the line it appeared on.
+type opener struct {
+	tok  token.Token
+	line int
+	kind braceKind
+}
 
-	sig := full[:openIdx+1]
-	body := full[openIdx+1:]
+// braceKind refines "{" openers whose closer needs more than "}".
+type braceKind int
 
-	// remove comments only from the body (keep signature intact)
-	cleanBody := removeComments(body)
+const (
+	braceBlock  braceKind = iota
+	braceLit              // composite literal: elements need a trailing comma
+	braceCalled           // body of an immediately called func literal: "}()"
+	braceHeader           // composite literal in a for/if/switch header: never cut inside
+)
 
-	// split into lines
-	sigLines := splitKeep(sig)
-	bodyLines := splitKeep(cleanBody)
+// lineEnd is the scanner state after the last token of a line.
+type lineEnd struct {
+	last token.Token
+	open []opener // outermost first
+}
 
-	// how many body lines can we keep?
-	remaining := maxLines - len(sigLines)
-	if remaining <= 0 {
-		out := append([]string{}, sigLines...)
-		out = append(out, "// ... trimmed ...")
-		outStr := strings.Join(out, "\n")
-		return ensureTrailingNL(outStr), lineCount(outStr)
+// capAtStatement keeps at most maxLines lines, cutting after the last line that
+// ends a statement (or opens a block/list) within the body, then appends the
+// marker and the closers for every still-open bracket. The marker and closers
+// count against maxLines.
+func capAtStatement(s string, maxLines int) string {
+	lines := strings.Split(s, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return s
 	}
 
-	if len(bodyLines) <= remaining {
-		out := append([]string{}, sigLines...)
-		out = append(out, bodyLines...)
-		outStr := strings.Join(out, "\n")
-		return ensureTrailingNL(outStr), lineCount(outStr)
+	ends, bodyLine := scanLineEnds(s)
+	if bodyLine == 0 {
+		return s // nothing to cut into (e.g. "type T int" spanning many lines)
 	}
 
-	// keep top remaining lines from body
-	out := append([]string{}, sigLines...)
-	out = append(out, bodyLines[:remaining]...)
-	out = append(out, "// ... trimmed ...")
-	outStr := strings.Join(out, "\n")
-	return ensureTrailingNL(outStr), lineCount(outStr)
+	// last safe line whose output fits the cap; if none does, the first safe line
+	cut := 0
+	for l := bodyLine; l <= len(lines); l++ {
+		e, ok := ends[l]
+		if !ok || !safeCut(e) {
+			continue
+		}
+		if l+1+len(closers(lines, e.open)) <= maxLines || cut == 0 {
+			cut = l
+		}
+		if l+3 > maxLines && cut != 0 {
+			break // a later line plus marker and a closer cannot fit
+		}
+	}
+	if cut == 0 || strings.Trim(strings.Join(lines[cut:], ""), "})] \t") == "" {
+		return s // nothing but closers would be dropped
+	}
+
+	out := append([]string{}, lines[:cut]...)
+	out = append(out, markerIndent(lines, cut, ends[cut].last)+trimmedMarker)
+	out = append(out, closers(lines, ends[cut].open)...)
+	return strings.Join(out, "\n")
 }
 
-func removeComments(s string) string {
-	// order matters: strip block comments first, then line comments
-	s = mlCommentRe.ReplaceAllString(s, "")
-	s = slCommentRe.ReplaceAllString(s, "")
-	// trim trailing spaces/newlines from comment removal
-	return strings.TrimRight(s, "\n")
+// closers returns the lines closing every bracket in open, innermost first.
+func closers(lines []string, open []opener) []string {
+	var out []string
+	for i := len(open) - 1; i >= 0; i-- {
+		c := closerFor(open[i].tok)
+		if open[i].kind == braceCalled {
+			c += "()"
+		}
+		// ")" / "]" right after a closer must stay on its line: a newline after "}"
+		// would insert a semicolon inside the call/index expression.
+		if c != "}" && len(out) > 0 {
+			out[len(out)-1] = strings.TrimSuffix(out[len(out)-1], ",") + c
+		} else {
+			out = append(out, indentOf(lines[open[i].line-1])+c)
+		}
+		if i > 0 && open[i-1].kind == braceLit {
+			out[len(out)-1] += ","
+		}
+	}
+	return out
 }
 
-// splitKeep splits by \n without dropping a trailing final line if empty
-func splitKeep(s string) []string {
-	// normalize already ensured \n, but we won’t trim right
-	return strings.Split(s, "\n")
+// markerIndent aligns the marker with the first dropped line, unless that line
+// is itself a closer; then it goes one level inside the last kept line if that opened a block.
+func markerIndent(lines []string, cut int, last token.Token) string {
+	next := strings.TrimLeft(lines[cut], " \t")
+	if next != "" && !strings.ContainsAny(next[:1], "})]") {
+		return indentOf(lines[cut])
+	}
+	ind := indentOf(lines[cut-1])
+	switch last {
+	case token.LBRACE, token.LPAREN, token.COLON:
+		ind += "\t"
+	}
+	return ind
 }
 
-func normalizeNewlines(s string) string {
-	s = strings.ReplaceAll(s, "\r\n", "\n")
-	s = strings.ReplaceAll(s, "\r", "\n")
-	lines := strings.Split(s, "\n")
-	for i := range lines {
-		lines[i] = strings.TrimRight(lines[i], " \t")
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// scanLineEnds records the state at each line end and the line of the body's "{"
+// (the first brace opened outside any parentheses/brackets), or 0 if none.
+// Lines inside a multi-line comment get no entry, so a cut never splits one.
+func scanLineEnds(s string) (map[int]lineEnd, int) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(s))
+	var sc goscanner.Scanner
+	sc.Init(file, []byte(s), nil, goscanner.ScanComments)
+
+	kinds := braceKinds(s)
+	ends := map[int]lineEnd{}
+	inComment := map[int]bool{}
+	var stack []opener
+	bodyLine := 0
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		line := file.Line(pos)
+		if tok == token.COMMENT {
+			for l := line; l < line+strings.Count(lit, "\n"); l++ {
+				inComment[l] = true
+			}
+			continue
+		}
+		switch tok {
+		case token.LBRACE, token.LPAREN, token.LBRACK:
+			if tok == token.LBRACE && bodyLine == 0 && len(stack) == 0 {
+				bodyLine = line
+			}
+			stack = append(stack, opener{tok: tok, line: line, kind: kinds[file.Offset(pos)]})
+		case token.RBRACE, token.RPAREN, token.RBRACK:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		ends[line] = lineEnd{last: tok, open: slices.Clone(stack)}
 	}
-	return strings.Join(lines, "\n")
+	for l := range inComment {
+		delete(ends, l)
+	}
+	return ends, bodyLine
 }
 
-func capByLines(s string, maxLines int) (string, int) {
-	lines := strings.Split(s, "\n")
-	if len(lines) <= maxLines {
-		out := strings.Join(lines, "\n")
-		return ensureTrailingNL(out), lineCount(out)
+func safeCut(e lineEnd) bool {
+	if len(e.open) == 0 {
+		return false
+	}
+	for _, o := range e.open {
+		if o.kind == braceHeader {
+			return false
+		}
+	}
+	top := e.open[len(e.open)-1]
+	switch e.last {
+	case token.SEMICOLON, token.LBRACE, token.LPAREN:
+		return true
+	case token.COMMA: // list element, not "case a,\n b:" or "x,\n y := ..."
+		return top.tok != token.LBRACE || top.kind == braceLit
+	case token.COLON: // case clause or label, not a "key:" inside a literal
+		return top.tok == token.LBRACE && top.kind != braceLit
+	}
+	return false
+}
+
+// braceKinds classifies the "{" offsets in s that are not plain blocks.
+// Unparsable input yields none, so every brace is then treated as a block.
+func braceKinds(s string) map[int]braceKind {
+	fset := token.NewFileSet()
+	var root ast.Node
+	base := 0
+	const header = "package p\n"
+	if f, err := parser.ParseFile(fset, "", header+s, parser.SkipObjectResolution); err == nil {
+		root, base = f, len(header)
+	} else if x, err := parser.ParseExprFrom(fset, "", s, parser.SkipObjectResolution); err == nil {
+		root = x
+	}
+	out := map[int]braceKind{}
+	if root == nil {
+		return out
+	}
+	offset := func(p token.Pos) int { return fset.PositionFor(p, false).Offset - base }
+	var headers [][2]token.Pos // [stmt start, body "{")
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.CompositeLit:
+			out[offset(t.Lbrace)] = braceLit
+		case *ast.CallExpr:
+			if fl, ok := t.Fun.(*ast.FuncLit); ok && fl.Body != nil {
+				out[offset(fl.Body.Lbrace)] = braceCalled
+			}
+		case *ast.ForStmt:
+			headers = append(headers, [2]token.Pos{t.Pos(), t.Body.Lbrace})
+		case *ast.RangeStmt:
+			headers = append(headers, [2]token.Pos{t.Pos(), t.Body.Lbrace})
+		case *ast.IfStmt:
+			headers = append(headers, [2]token.Pos{t.Pos(), t.Body.Lbrace})
+		case *ast.SwitchStmt:
+			headers = append(headers, [2]token.Pos{t.Pos(), t.Body.Lbrace})
+		case *ast.TypeSwitchStmt:
+			headers = append(headers, [2]token.Pos{t.Pos(), t.Body.Lbrace})
+		}
+		return true
+	})
+	for _, h := range headers {
+		for off, k := range out {
+			if k == braceLit && off >= offset(h[0]) && off < offset(h[1]) {
+				out[off] = braceHeader
+			}
+		}
+	}
+	return out
+}
+
+func closerFor(open token.Token) string {
+	switch open {
+	case token.LPAREN:
+		return ")"
+	case token.LBRACK:
+		return "]"
+	default:
+		return "}"
+	}
+}
+
+// parses reports whether code is a valid declaration (func, method, type) or,
+// failing that, a valid expression (function literal).
+func parses(code string) bool {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "", "package p\n"+code, parser.SkipObjectResolution); err == nil {
+		return true
 	}
-	out := strings.Join(lines[:maxLines], "\n") + "\n// ... trimmed ...\n"
-	return out, lineCount(out)
+	_, err := parser.ParseExprFrom(fset, "", code, parser.SkipObjectResolution)
+	return err == nil
 }
@@ -0,0 +1,155 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemoveComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "line comment",
+			in:   "func f() {\n\tx := 1 // one\n\t_ = x\n}",
+			want: "func f() {\n\tx := 1 \n\t_ = x\n}",
+		},
+		{
+			name: "comment-only line dropped",
+			in:   "func f() {\n\t// why\n\tx := 1\n\n\t_ = x\n}",
+			want: "func f() {\n\tx := 1\n\n\t_ = x\n}",
+		},
+		{
+			name: "block comment at line start",
+			in:   "func f() {\n\t/* x */ y := 1\n\t_ = y\n}",
+			want: "func f() {\n\ty := 1\n\t_ = y\n}",
+		},
+		{
+			name: "block comment between blanks",
+			in:   "func f() {\n\ty := /* x */ 1\n\t_ = y\n}",
+			want: "func f() {\n\ty := 1\n\t_ = y\n}",
+		},
+		{
+			name: "block comment between tokens",
+			in:   "func f() {\n\ty:=/* x */1\n\t_ = y\n}",
+			want: "func f() {\n\ty:= 1\n\t_ = y\n}",
+		},
+		{
+			name: "multi-line block comment keeps the newline",
+			in:   "func f() {\n\tx := 1 /* a\n\tb */ _ = x\n}",
+			want: "func f() {\n\tx := 1 \n_ = x\n}",
+		},
+		{
+			name: "comment markers inside literals",
+			in:   "func f() {\n\ta := \"/* not */ // a comment\"\n\tb := `// raw\n/* too */`\n\tc := '/'\n\t_, _, _ = a, b, c\n}",
+			want: "func f() {\n\ta := \"/* not */ // a comment\"\n\tb := `// raw\n/* too */`\n\tc := '/'\n\t_, _, _ = a, b, c\n}",
+		},
+		{
+			name: "elision marker kept",
+			in:   "func f() {\n\tif true {\n\t\t// ... elided 3 lines\n\t}\n}",
+			want: "func f() {\n\tif true {\n\t\t// ... elided 3 lines\n\t}\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeComments(tt.in); got != tt.want {
+				t.Errorf("removeComments(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimFunctionCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		maxLines int
+		contains []string // substrings the output must keep
+	}{
+		{
+			name:     "short function untouched",
+			in:       "func f() int {\n\treturn 1\n}",
+			maxLines: 10,
+			contains: []string{"return 1"},
+		},
+		{
+			name:     "plain statements",
+			in:       "func f() {\n" + strings.Repeat("\tprintln(1)\n", 20) + "}",
+			maxLines: 8,
+			contains: []string{trimmedMarker},
+		},
+		{
+			name:     "raw string keeps trailing blanks",
+			in:       "func f() string {\n\ts := `a  \nb\t\n`\n\treturn s\n}",
+			maxLines: 10,
+			contains: []string{"`a  \nb\t\n`"},
+		},
+		{
+			name: "cap around a long raw string",
+			in: "func f() {\n\tx := 1\n\ts := `\n" + strings.Repeat("line\n", 20) + "`\n\t_ = s\n" +
+				strings.Repeat("\tx++\n", 10) + "}",
+			maxLines: 6,
+			contains: []string{"x := 1", trimmedMarker},
+		},
+		{
+			name: "comment opener inside strings",
+			in: "func f() {\n\ta := \"/*\"\n\tb := `/*`\n" + strings.Repeat("\tprintln(a, b)\n", 10) +
+				"\tc := \"*/\"\n\t_ = c\n}",
+			maxLines: 7,
+			contains: []string{`a := "/*"`, "b := `/*`", trimmedMarker},
+		},
+		{
+			name:     "composite literal",
+			in:       "func f() []int {\n\treturn []int{\n" + strings.Repeat("\t\t1,\n", 20) + "\t}\n}",
+			maxLines: 8,
+			contains: []string{"return []int{", trimmedMarker},
+		},
+		{
+			name: "nested composite literal",
+			in: "func f() map[string][]int {\n\treturn map[string][]int{\n\t\t\"a\": {\n" +
+				strings.Repeat("\t\t\t1,\n", 20) + "\t\t},\n\t}\n}",
+			maxLines: 9,
+			contains: []string{trimmedMarker},
+		},
+		{
+			name:     "called func literal",
+			in:       "func f() {\n\tgo func() {\n" + strings.Repeat("\t\tprintln(1)\n", 20) + "\t}()\n}",
+			maxLines: 8,
+			contains: []string{"go func() {", trimmedMarker, "}()"},
+		},
+		{
+			name:     "func literal expression",
+			in:       "func() {\n" + strings.Repeat("\tprintln(1)\n", 20) + "}",
+			maxLines: 6,
+			contains: []string{trimmedMarker},
+		},
+		{
+			name: "literal in a for header",
+			in: "func f() {\n\tfor _, v := range []int{\n" + strings.Repeat("\t\t1,\n", 10) + "\t} {\n" +
+				strings.Repeat("\t\tprintln(v)\n", 10) + "\t}\n}",
+			maxLines: 16,
+			contains: []string{"} {", trimmedMarker},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, lines, ok := trimFunctionCode(tt.in, tt.maxLines, false)
+			if !ok {
+				t.Errorf("output does not parse:\n%s", code)
+			}
+			if n := strings.Count(code, "\n"); n != lines {
+				t.Errorf("lines = %d, output has %d", lines, n)
+			}
+			if lines > tt.maxLines {
+				t.Errorf("%d lines, cap is %d:\n%s", lines, tt.maxLines, code)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(code, s) {
+					t.Errorf("output lost %q:\n%s", s, code)
+				}
+			}
+		})
+	}
+}
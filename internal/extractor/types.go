@@ -0,0 +1,216 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// methodSets maps "<pkgKey>.<TypeName>" -> methods declared on T or *T.
+type methodSets map[string][]model.TypeMethod
+
+// collectMethodSets walks every unit once so that a type's method set includes
+// methods declared in sibling files of the same package (regardless of line filters).
+func collectMethodSets(units []scanner.FileUnit) methodSets {
+	out := methodSets{}
+	for _, u := range units {
+		if u.File == nil {
+			continue
+		}
+		pk := pkgKey(u)
+		for _, d := range u.File.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Name == nil || fd.Recv == nil || len(fd.Recv.List) == 0 {
+				continue
+			}
+			base, ptr := recvTypeName(fd.Recv.List[0].Type)
+			if base == "" {
+				continue
+			}
+			recv := base
+			if ptr {
+				recv = "*" + base
+			}
+			key := pk + "." + base
+			out[key] = append(out[key], model.TypeMethod{
+				Name:      fd.Name.Name,
+				Recv:      recv,
+				Signature: funcSignature(u, fd),
+			})
+		}
+	}
+	for k := range out {
+		ms := out[k]
+		sort.SliceStable(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+	}
+	return out
+}
+
+func (e *ASTExtractor) extractTypes(u scanner.FileUnit, methods methodSets) (out []*core.TypeNode) {
+	if u.File == nil {
+		return nil
+	}
+	pk := pkgKey(u)
+	for _, d := range u.File.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		grouped := gd.Lparen.IsValid()
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name == nil {
+				continue
+			}
+
+			// A lone "type T ..." keeps its keyword and doc; grouped specs get "type " prepended.
+			startPos, code, doc := ts.Pos(), "type "+sliceByPos(u.Src, u.Fset, ts.Pos(), ts.End()), ts.Doc
+			if !grouped {
+				startPos, code = gd.Pos(), sliceByPos(u.Src, u.Fset, gd.Pos(), gd.End())
+				if doc == nil {
+					doc = gd.Doc
+				}
+			}
+			trimmed, _, valid := e.trim(code)
+			if !valid && e.DropInvalid {
+				continue
+			}
+
+			tn := &core.TypeNode{
+				Name:        ts.Name.Name,
+				Kind:        typeKind(ts),
+				Signature:   typeSignature(u, ts),
+				StartLine:   u.Fset.PositionFor(startPos, true).Line,
+				EndLine:     u.Fset.PositionFor(ts.End(), true).Line,
+				Code:        ensureTrailingNL(trimmed),
+				Tokens:      e.countTokens(trimmed),
+				Doc:         docText(doc),
+				Comments:    e.listComments(u, startPos, ts.End()),
+				InvalidCode: !valid,
+				IsTestFile:  testFileRe.MatchString(u.RelPath),
+				Aspects:     make(map[core.AspectKind]any),
+			}
+
+			switch tt := ts.Type.(type) {
+			case *ast.StructType:
+				tn.Fields, tn.Embeds = structFields(u, tt)
+			case *ast.InterfaceType:
+				tn.Methods, tn.Embeds = interfaceMembers(u, tt)
+			}
+			if tn.Kind != core.TypeInterface {
+				tn.Methods = methods[pk+"."+tn.Name]
+			}
+			out = append(out, tn)
+		}
+	}
+	return out
+}
+
+func typeKind(ts *ast.TypeSpec) string {
+	if ts.Assign.IsValid() {
+		return core.TypeAlias
+	}
+	switch ts.Type.(type) {
+	case *ast.StructType:
+		return core.TypeStruct
+	case *ast.InterfaceType:
+		return core.TypeInterface
+	default:
+		return core.TypeNamed
+	}
+}
+
+// typeSignature renders a one-line header: "type T[P any] struct", "type ID = string", ...
+func typeSignature(u scanner.FileUnit, ts *ast.TypeSpec) string {
+	head := strings.TrimSpace(sliceByPos(u.Src, u.Fset, ts.Name.Pos(), ts.Type.Pos()))
+	if ts.Assign.IsValid() {
+		head = strings.TrimSpace(strings.TrimSuffix(head, "="))
+		return "type " + head + " = " + exprText(u, ts.Type)
+	}
+	switch ts.Type.(type) {
+	case *ast.StructType:
+		return "type " + head + " struct"
+	case *ast.InterfaceType:
+		return "type " + head + " interface"
+	default:
+		return "type " + head + " " + exprText(u, ts.Type)
+	}
+}
+
+func structFields(u scanner.FileUnit, st *ast.StructType) (fields []model.TypeField, embeds []string) {
+	if st.Fields == nil {
+		return nil, nil
+	}
+	for _, f := range st.Fields.List {
+		typ := exprText(u, f.Type)
+		tag := ""
+		if f.Tag != nil {
+			if v, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = v
+			}
+		}
+		if len(f.Names) == 0 {
+			fields = append(fields, model.TypeField{Type: typ, Tag: tag, Embedded: true})
+			embeds = append(embeds, typ)
+			continue
+		}
+		for _, n := range f.Names {
+			fields = append(fields, model.TypeField{Name: n.Name, Type: typ, Tag: tag})
+		}
+	}
+	return fields, embeds
+}
+
+func interfaceMembers(u scanner.FileUnit, it *ast.InterfaceType) (methods []model.TypeMethod, embeds []string) {
+	if it.Methods == nil {
+		return nil, nil
+	}
+	for _, f := range it.Methods.List {
+		if len(f.Names) == 0 {
+			embeds = append(embeds, exprText(u, f.Type))
+			continue
+		}
+		for _, n := range f.Names {
+			methods = append(methods, model.TypeMethod{
+				Name:      n.Name,
+				Signature: n.Name + exprText(u, f.Type),
+			})
+		}
+	}
+	return methods, embeds
+}
+
+// recvTypeName unwraps "*T", "T[K, V]" and "*T[K]" receivers to ("T", isPtr).
+func recvTypeName(x ast.Expr) (string, bool) {
+	ptr := false
+	if s, ok := x.(*ast.StarExpr); ok {
+		ptr, x = true, s.X
+	}
+	switch t := x.(type) {
+	case *ast.IndexExpr:
+		x = t.X
+	case *ast.IndexListExpr:
+		x = t.X
+	}
+	if id, ok := x.(*ast.Ident); ok {
+		return id.Name, ptr
+	}
+	return "", ptr
+}
+
+// exprText returns the source of x collapsed onto a single line.
+func exprText(u scanner.FileUnit, x ast.Expr) string {
+	return strings.Join(strings.Fields(sliceByPos(u.Src, u.Fset, x.Pos(), x.End())), " ")
+}
+
+// pkgKey identifies a package by directory + package clause (keeps foo and foo_test apart).
+func pkgKey(u scanner.FileUnit) string {
+	return path.Dir(u.RelPath) + "|" + u.File.Name.Name
+}
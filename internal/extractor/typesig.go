@@ -0,0 +1,123 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// typedSignature resolves a declaration or literal through the package's type
+// information: the signature with every type qualified by its import path,
+// e.g. "func (*example.com/p.R).List(ctx context.Context) ([]example.com/p.R, error)",
+// and the named types it references ("context.Context", ...). Both are empty
+// when the file was loaded without type information.
+func typedSignature(u scanner.FileUnit, fn ast.Node) (string, []string) {
+	if u.Info == nil {
+		return "", nil
+	}
+	var (
+		sig  *types.Signature
+		text string
+	)
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		obj, _ := u.Info.Defs[fn.Name].(*types.Func)
+		if obj == nil {
+			return "", nil
+		}
+		sig, text = obj.Signature(), types.ObjectString(obj, nil)
+	case *ast.FuncLit:
+		sig, _ = u.Info.TypeOf(fn).(*types.Signature)
+		if sig == nil {
+			return "", nil
+		}
+		text = types.TypeString(sig, nil)
+	}
+	return text, typeRefs(sig)
+}
+
+// typeRefs lists the named types a signature mentions (receiver, type
+// parameter constraints, parameters, results), qualified and sorted.
+func typeRefs(sig *types.Signature) []string {
+	seen := map[string]bool{}
+	visited := map[types.Type]bool{}
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		if t == nil || visited[t] {
+			return
+		}
+		visited[t] = true
+		switch t := t.(type) {
+		case *types.Named:
+			if obj := t.Obj(); obj.Pkg() != nil {
+				seen[obj.Pkg().Path()+"."+obj.Name()] = true
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.Alias:
+			if obj := t.Obj(); obj.Pkg() != nil {
+				seen[obj.Pkg().Path()+"."+obj.Name()] = true
+			}
+			for i := 0; i < t.TypeArgs().Len(); i++ {
+				walk(t.TypeArgs().At(i))
+			}
+		case *types.TypeParam:
+			walk(t.Constraint())
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case *types.Chan:
+			walk(t.Elem())
+		case *types.Signature:
+			tuple(t.Params(), walk)
+			tuple(t.Results(), walk)
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				walk(t.Field(i).Type())
+			}
+		case *types.Interface:
+			for i := 0; i < t.NumEmbeddeds(); i++ {
+				walk(t.EmbeddedType(i))
+			}
+			for i := 0; i < t.NumExplicitMethods(); i++ {
+				walk(t.ExplicitMethod(i).Type())
+			}
+		case *types.Union:
+			for i := 0; i < t.Len(); i++ {
+				walk(t.Term(i).Type())
+			}
+		}
+	}
+
+	if r := sig.Recv(); r != nil {
+		walk(r.Type())
+	}
+	for _, tps := range []*types.TypeParamList{sig.RecvTypeParams(), sig.TypeParams()} {
+		for i := 0; i < tps.Len(); i++ {
+			walk(tps.At(i))
+		}
+	}
+	walk(sig)
+
+	out := make([]string, 0, len(seen))
+	for k := range seen {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func tuple(t *types.Tuple, walk func(types.Type)) {
+	for i := 0; i < t.Len(); i++ {
+		walk(t.At(i).Type())
+	}
+}
@@ -0,0 +1,181 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+type valueType int
+
+const (
+	typeBool valueType = iota
+	typeNumber
+	typeString
+)
+
+func (t valueType) String() string {
+	return [...]string{"bool", "number", "string"}[t]
+}
+
+type field struct {
+	typ valueType
+	get func(*model.Record) any // bool | float64 | string
+}
+
+func str(f func(*model.Record) string) field {
+	return field{typeString, func(r *model.Record) any { return f(r) }}
+}
+
+func num(f func(*model.Record) int) field {
+	return field{typeNumber, func(r *model.Record) any { return float64(f(r)) }}
+}
+
+func flag(f func(*model.Record) bool) field {
+	return field{typeBool, func(r *model.Record) any { return f(r) }}
+}
+
+// fields are the names an expression can use. Aspect fields read as zero
+// values ("", 0) when the aspect was not computed.
+var fields = map[string]field{
+	"repo":       str(func(r *model.Record) string { return r.Repo }),
+	"commit":     str(func(r *model.Record) string { return r.Commit }),
+	"lang":       str(func(r *model.Record) string { return r.Lang }),
+	"kind":       str(func(r *model.Record) string { return r.Kind }),
+	"module":     str(func(r *model.Record) string { return r.Module }),
+	"path":       str(func(r *model.Record) string { return r.Path }),
+	"symbol":     str(func(r *model.Record) string { return r.Symbol }),
+	"name":       str(name),
+	"signature":  str(func(r *model.Record) string { return r.Signature }),
+	"code":       str(func(r *model.Record) string { return r.Code }),
+	"doc":        str(func(r *model.Record) string { return r.Doc }),
+	"parent":     str(func(r *model.Record) string { return r.Parent }),
+	"file_class": str(func(r *model.Record) string { return strings.Join(r.FileClass, ",") }),
+
+	// type-resolved signature ("" without type information)
+	"typed_signature": str(func(r *model.Record) string { return r.TypedSig }),
+	"type_refs":       str(func(r *model.Record) string { return strings.Join(r.TypeRefs, ",") }),
+
+	"start_line": num(func(r *model.Record) int { return r.StartLine }),
+	"end_line":   num(func(r *model.Record) int { return r.EndLine }),
+	"lines":      num(func(r *model.Record) int { return r.EndLine - r.StartLine + 1 }),
+	"tokens":     num(func(r *model.Record) int { return r.Tokens }),
+	"captures":   num(func(r *model.Record) int { return len(r.Captures) }),
+	"elided":     num(func(r *model.Record) int { return len(r.Elided) }),
+
+	"exported": flag(exported),
+	"test":     flag(func(r *model.Record) bool { return r.TestFile }),
+	"invalid":  flag(func(r *model.Record) bool { return r.InvalidCode }),
+	"flagged":  flag(func(r *model.Record) bool { return r.Flagged }),
+
+	// aspects
+	"visibility": str(func(r *model.Record) string {
+		if r.Selection != nil {
+			return r.Selection.Visibility
+		}
+		if exported(r) {
+			return "exported"
+		}
+		return "unexported"
+	}),
+	"reason": str(func(r *model.Record) string {
+		if r.Selection != nil {
+			return r.Selection.Reason
+		}
+		return ""
+	}),
+	"score": {typeNumber, func(r *model.Record) any {
+		if r.Selection != nil {
+			return r.Selection.Score
+		}
+		return 0.0
+	}},
+	"callers": num(func(r *model.Record) int {
+		if r.CallGraph != nil {
+			return len(r.CallGraph.Callers)
+		}
+		return 0
+	}),
+	"callees": num(func(r *model.Record) int {
+		if r.CallGraph != nil {
+			return len(r.CallGraph.Callees)
+		}
+		return 0
+	}),
+	"context_refs":  num(func(r *model.Record) int { return len(r.ContextRefs) }),
+	"decl_refs":     num(func(r *model.Record) int { return len(r.DeclRefs) }),
+	"imports":       num(func(r *model.Record) int { return len(r.Imports) }),
+	"neighbors":     num(func(r *model.Record) int { return len(r.Neighbors) }),
+	"tests":         num(func(r *model.Record) int { return len(r.Tests) }),
+	"last_commit":   str(func(r *model.Record) string { return history(r).LastCommit }),
+	"last_modified": str(func(r *model.Record) string { return history(r).LastModified }),
+	"authors":       num(func(r *model.Record) int { return history(r).Authors }),
+	"commits":       num(func(r *model.Record) int { return history(r).Commits }),
+	"age_days":      num(func(r *model.Record) int { return history(r).AgeDays }),
+	"idle_days":     num(func(r *model.Record) int { return history(r).IdleDays }),
+	"cyclomatic":    num(func(r *model.Record) int { return complexity(r).Cyclomatic }),
+	"cognitive":     num(func(r *model.Record) int { return complexity(r).Cognitive }),
+	"nesting":       num(func(r *model.Record) int { return complexity(r).MaxNesting }),
+	"statements":    num(func(r *model.Record) int { return complexity(r).Statements }),
+	"params":        num(func(r *model.Record) int { return complexity(r).Params }),
+	"results":       num(func(r *model.Record) int { return complexity(r).Results }),
+	"returns":       num(func(r *model.Record) int { return complexity(r).Returns }),
+	"sentinels":     num(func(r *model.Record) int { return len(errorsOf(r).Sentinels) }),
+	"panics":        num(func(r *model.Record) int { return len(errorsOf(r).Panics) }),
+	"goroutines":    num(func(r *model.Record) int { return concurrencyOf(r).Goroutines }),
+	"sync":          num(func(r *model.Record) int { return len(concurrencyOf(r).Sync) }),
+}
+
+func concurrencyOf(r *model.Record) *model.Concurrency {
+	if r.Concurrency != nil {
+		return r.Concurrency
+	}
+	return &model.Concurrency{}
+}
+
+func errorsOf(r *model.Record) *model.Errors {
+	if r.Errors != nil {
+		return r.Errors
+	}
+	return &model.Errors{}
+}
+
+func complexity(r *model.Record) *model.Complexity {
+	if r.Complexity != nil {
+		return r.Complexity
+	}
+	return &model.Complexity{}
+}
+
+func history(r *model.Record) *model.History {
+	if r.History != nil {
+		return r.History
+	}
+	return &model.History{}
+}
+
+// name is the declared name: "Get" for "*Cache.Get", "Run" for the closure "Run$1".
+func name(r *model.Record) string {
+	s := r.Symbol[strings.LastIndex(r.Symbol, ".")+1:]
+	s, _, _ = strings.Cut(s, "$")
+	return s
+}
+
+// exported reports whether the name is exported; closures follow their enclosing function.
+func exported(r *model.Record) bool {
+	c, _ := utf8.DecodeRuneInString(name(r))
+	return unicode.IsUpper(c)
+}
+
+// Fields lists the field names an expression can use.
+func Fields() []string {
+	out := make([]string, 0, len(fields))
+	for k := range fields {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
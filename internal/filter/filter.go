@@ -0,0 +1,291 @@
+// Package filter compiles record filter expressions such as
+//
+//	visibility == "exported" && lines >= 5 && !test && score > 0.6 && path =~ "^internal/"
+//
+// Operators, loosest first: ||, &&, then comparisons == != < <= > >= and
+// regex matches =~ !~ (right side a string literal); unary ! binds tightest,
+// as in Go, so !a == b is (!a) == b. Operands are record fields (see Fields),
+// "double-quoted" or `raw` strings, numbers, true and false.
+// Expressions are type-checked when parsed.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// Expr is a compiled filter. A nil *Expr matches every record.
+type Expr struct {
+	src  string
+	root node
+}
+
+type node struct {
+	typ  valueType
+	eval func(*model.Record) any
+}
+
+// Parse compiles src; an empty (or blank) src yields a nil Expr.
+func Parse(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+	p := &parser{toks: toks}
+	root, err := p.or()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("unexpected %q", p.peek().text)
+	}
+	if err == nil && root.typ != typeBool {
+		err = fmt.Errorf("expression is a %s, want bool", root.typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// MustParse is Parse for expressions known to be valid; it panics on error.
+func MustParse(src string) *Expr {
+	e, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// And combines filters; nil operands are ignored.
+func And(a, b *Expr) *Expr {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &Expr{
+		src: "(" + a.src + ") && (" + b.src + ")",
+		root: node{typeBool, func(r *model.Record) any {
+			return a.root.eval(r).(bool) && b.root.eval(r).(bool)
+		}},
+	}
+}
+
+// Match reports whether r passes the filter.
+func (e *Expr) Match(r *model.Record) bool {
+	return e == nil || e.root.eval(r).(bool)
+}
+
+// Keep filters recs in place and returns the matching prefix.
+func (e *Expr) Keep(recs []model.Record) []model.Record {
+	if e == nil {
+		return recs
+	}
+	out := recs[:0]
+	for i := range recs {
+		if e.Match(&recs[i]) {
+			out = append(out, recs[i])
+		}
+	}
+	return out
+}
+
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.src
+}
+
+// ---------- parser ----------
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("col %d: %s", p.peek().pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) or() (node, error) {
+	return p.binaryBool("||", p.and, func(a, b func(*model.Record) any) func(*model.Record) any {
+		return func(r *model.Record) any { return a(r).(bool) || b(r).(bool) }
+	})
+}
+
+func (p *parser) and() (node, error) {
+	return p.binaryBool("&&", p.comparison, func(a, b func(*model.Record) any) func(*model.Record) any {
+		return func(r *model.Record) any { return a(r).(bool) && b(r).(bool) }
+	})
+}
+
+func (p *parser) binaryBool(op string, operand func() (node, error), combine func(a, b func(*model.Record) any) func(*model.Record) any) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return node{}, err
+	}
+	for p.accept(op) {
+		r, err := operand()
+		if err != nil {
+			return node{}, err
+		}
+		if l.typ != typeBool || r.typ != typeBool {
+			return node{}, fmt.Errorf("%s needs bool operands, have %s and %s", op, l.typ, r.typ)
+		}
+		l = node{typeBool, combine(l.eval, r.eval)}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		if x.typ != typeBool {
+			return node{}, fmt.Errorf("! needs a bool operand, have %s", x.typ)
+		}
+		return node{typeBool, func(r *model.Record) any { return !x.eval(r).(bool) }}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) comparison() (node, error) {
+	l, err := p.unary()
+	if err != nil {
+		return node{}, err
+	}
+	t := p.peek()
+	if t.kind != tokOp {
+		return l, nil
+	}
+	switch t.text {
+	case "=~", "!~":
+		p.next()
+		lit := p.next()
+		if lit.kind != tokString {
+			return node{}, fmt.Errorf("col %d: %s needs a string literal pattern", lit.pos+1, t.text)
+		}
+		if l.typ != typeString {
+			return node{}, fmt.Errorf("%s needs a string on the left, have %s", t.text, l.typ)
+		}
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return node{}, fmt.Errorf("col %d: %w", lit.pos+1, err)
+		}
+		want := t.text == "=~"
+		return node{typeBool, func(r *model.Record) any { return re.MatchString(l.eval(r).(string)) == want }}, nil
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		r, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		if l.typ != r.typ {
+			return node{}, fmt.Errorf("col %d: cannot compare %s %s %s", t.pos+1, l.typ, t.text, r.typ)
+		}
+		if l.typ == typeBool && t.text != "==" && t.text != "!=" {
+			return node{}, fmt.Errorf("col %d: bools only support == and !=", t.pos+1)
+		}
+		return node{typeBool, compare(t.text, l, r)}, nil
+	}
+	return l, nil
+}
+
+func compare(op string, l, r node) func(*model.Record) any {
+	return func(rec *model.Record) any {
+		a, b := l.eval(rec), r.eval(rec)
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+		var c int
+		switch a := a.(type) {
+		case float64:
+			c = cmp(a, b.(float64))
+		case string:
+			c = cmp(a, b.(string))
+		}
+		switch op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c >= 0
+	}
+}
+
+func cmp[T float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return node{typeString, func(*model.Record) any { return t.text }}, nil
+	case tokNumber:
+		return node{typeNumber, func(*model.Record) any { return t.num }}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			v := t.text == "true"
+			return node{typeBool, func(*model.Record) any { return v }}, nil
+		}
+		f, ok := fields[t.text]
+		if !ok {
+			return node{}, fmt.Errorf("col %d: unknown field %q (have %s)", t.pos+1, t.text, strings.Join(Fields(), ", "))
+		}
+		return node{f.typ, f.get}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.or()
+			if err != nil {
+				return node{}, err
+			}
+			if !p.accept(")") {
+				return node{}, p.errorf("missing )")
+			}
+			return x, nil
+		}
+	case tokEOF:
+		return node{}, fmt.Errorf("col %d: unexpected end of expression", t.pos+1)
+	}
+	return node{}, fmt.Errorf("col %d: unexpected %q", t.pos+1, t.text)
+}
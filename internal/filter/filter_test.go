@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+func TestMatch(t *testing.T) {
+	rec := &model.Record{
+		Kind:      model.KindMethod,
+		Path:      "internal/cache/cache.go",
+		Symbol:    "Cache.Get",
+		StartLine: 10,
+		EndLine:   19,
+		Selection: &model.Selection{Score: 0.7, Visibility: "exported"},
+	}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// || is loosest, then &&
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"false && false || true", true},
+		{"false && (false || true)", false},
+
+		// comparisons bind tighter than && and ||
+		{"lines == 10 && score > 0.5", true},
+		{"lines < 5 || score > 0.5", true},
+		{"lines < 5 || score > 0.9 && exported", false},
+
+		// ! binds tightest: !a && b is (!a) && b
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"!test && exported", true},
+		{"!!exported", true},
+		{"!exported == false", true},
+
+		// fields, literals and regexes
+		{`name == "Get" && kind == "method"`, true},
+		{`visibility != "exported"`, false},
+		{`path =~ "^internal/"`, true},
+		{"path !~ `_test\\.go$`", true},
+		{`symbol >= "Cache" && symbol < "Cachf"`, true},
+		{"callers == 0 && sentinels == 0", true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		if got := e.Match(rec); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // substring of the error
+	}{
+		{"lines", "expression is a number, want bool"},
+		{`path`, "expression is a string, want bool"},
+		{"!lines > 3", "! needs a bool operand, have number"},
+		{"lines && exported", "&& needs bool operands, have number and bool"},
+		{`exported || "x"`, "|| needs bool operands, have bool and string"},
+		{`lines == "10"`, "cannot compare number == string"},
+		{"exported < true", "bools only support == and !="},
+		{"lines =~ `1`", "=~ needs a string on the left, have number"},
+		{"path =~ 3", "=~ needs a string literal pattern"},
+		{`path =~ "("`, "missing closing )"},
+		{"colour == 1", `unknown field "colour"`},
+		{"(exported", "missing )"},
+		{"exported &&", "unexpected end of expression"},
+		{"exported exported", `unexpected "exported"`},
+		{"lines < 3 < 4", `unexpected "<"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want error containing %q", tt.src, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	e, err := Parse("  ")
+	if err != nil || e != nil {
+		t.Fatalf("Parse(blank) = %v, %v; want nil, nil", e, err)
+	}
+	if !e.Match(&model.Record{}) {
+		t.Error("nil filter must match every record")
+	}
+}
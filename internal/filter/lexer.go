@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp // && || ! == != < <= > >= =~ !~ ( )
+)
+
+type token struct {
+	kind tokKind
+	text string // operator or identifier; unquoted value for strings
+	num  float64
+	pos  int // byte offset, for errors
+}
+
+// ops is ordered so two-byte operators match before their one-byte prefixes.
+var ops = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "!", "<", ">", "(", ")"}
+
+func lex(src string) ([]token, error) {
+	var out []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '`':
+			j := i + 1
+			for j < len(src) && src[j] != src[i] {
+				if c == '"' && src[j] == '\\' {
+					j++ // escaped rune; `raw` strings have no escapes
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("col %d: unterminated string", i+1)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("col %d: bad string %s", i+1, src[i:j+1])
+			}
+			out = append(out, token{kind: tokString, text: s, pos: i})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '.' || c == '-':
+			j := i + 1
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("col %d: bad number %q", i+1, src[i:j])
+			}
+			out = append(out, token{kind: tokNumber, text: src[i:j], num: n, pos: i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			out = append(out, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range ops {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("col %d: unexpected %q", i+1, c)
+			}
+			out = append(out, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(out, token{kind: tokEOF, pos: len(src)}), nil
+}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// Order is a compiled sort order over record fields, such as
+//
+//	cognitive,-lines
+//
+// Keys are comma-separated number or string fields (see Fields), ascending
+// unless prefixed with "-"; later keys break ties of earlier ones and records
+// that tie on every key keep their input order. Ordering by a complexity
+// metric gives a curriculum: simple functions first. A nil *Order leaves
+// records as they are.
+type Order struct {
+	src  string
+	keys []orderKey
+}
+
+type orderKey struct {
+	field field
+	desc  bool
+}
+
+// ParseOrder compiles src; an empty (or blank) src yields a nil Order.
+func ParseOrder(src string) (*Order, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+	o := &Order{src: src}
+	for _, k := range strings.Split(src, ",") {
+		k = strings.TrimSpace(k)
+		desc := strings.HasPrefix(k, "-")
+		k = strings.TrimPrefix(k, "-")
+		f, ok := fields[k]
+		if !ok {
+			return nil, fmt.Errorf("order: unknown field %q (have %s)", k, strings.Join(Fields(), ", "))
+		}
+		if f.typ == typeBool {
+			return nil, fmt.Errorf("order: %s is a bool, want a number or string field", k)
+		}
+		o.keys = append(o.keys, orderKey{f, desc})
+	}
+	return o, nil
+}
+
+// Sort orders recs in place.
+func (o *Order) Sort(recs []model.Record) {
+	if o == nil {
+		return
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, k := range o.keys {
+			a, b := k.field.get(&recs[i]), k.field.get(&recs[j])
+			var c int
+			switch a := a.(type) {
+			case float64:
+				c = cmp(a, b.(float64))
+			case string:
+				c = cmp(a, b.(string))
+			}
+			if c != 0 {
+				return (c < 0) != k.desc
+			}
+		}
+		return false
+	})
+}
+
+func (o *Order) String() string {
+	if o == nil {
+		return ""
+	}
+	return o.src
+}
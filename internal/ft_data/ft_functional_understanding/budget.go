@@ -0,0 +1,54 @@
+package ftfunctionalunderstanding
+
+import (
+	"encoding/json"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
+)
+
+// contextShedders drop optional user context, in order, from over-budget records.
+// Assistant turns are the answers and are never touched.
+var contextShedders = []func(*BaseContext){
+	func(c *BaseContext) { c.Neighbors = nil },
+	func(c *BaseContext) { c.Notes = nil },
+	func(c *BaseContext) { c.Declarations = nil },
+	func(c *BaseContext) { c.Imports = nil },
+}
+
+// WithTokenBudget counts the tokens of every generated record and, when
+// maxTokens > 0, sheds optional user context until it fits. Records that still
+// exceed the budget are dropped.
+func (g *Generator) WithTokenBudget(tok tokenizer.Counter, maxTokens int) *Generator {
+	g.tok = tok
+	g.maxTokens = maxTokens
+	return g
+}
+
+// fitBudget sets r.Tokens and reports whether r is within the budget.
+func (g *Generator) fitBudget(r *FineTuneRecord) bool {
+	if g.tok == nil {
+		return true
+	}
+	r.Tokens = g.count(r)
+	for _, shed := range contextShedders {
+		if g.maxTokens <= 0 || r.Tokens <= g.maxTokens {
+			return true
+		}
+		for _, c := range r.Conversations {
+			if c.Role == "user" && c.Context != nil {
+				shed(c.Context)
+			}
+		}
+		r.Tokens = g.count(r)
+	}
+	return g.maxTokens <= 0 || r.Tokens <= g.maxTokens
+}
+
+// count measures the conversations as serialized, which is what training sees.
+func (g *Generator) count(r *FineTuneRecord) int {
+	b, err := json.Marshal(r.Conversations)
+	if err != nil {
+		return 0
+	}
+	return g.tok.Count(string(b))
+}
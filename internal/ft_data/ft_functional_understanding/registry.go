@@ -1,6 +1,9 @@
 package ftfunctionalunderstanding
 
-import "github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+import (
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/tokenizer"
+)
 
 // QuestionRegistry holds registered strategies in order.
 type QuestionRegistry struct {
@@ -27,6 +30,9 @@ func (r *QuestionRegistry) Strategies() []QuestionStrategy {
 // Generator wires BaseContext (Builder) + Strategy list.
 type Generator struct {
 	registry *QuestionRegistry
+
+	tok       tokenizer.Counter // optional; see WithTokenBudget
+	maxTokens int
 }
 
 // NewGenerator with injected pieces (DI-friendly).
@@ -34,12 +40,14 @@ func NewGenerator(reg *QuestionRegistry) *Generator {
 	return &Generator{registry: reg}
 }
 
-// Generate runs all strategies and collects non-nil Q/A pairs.
+// Generate runs all strategies and collects non-nil Q/A pairs within the token budget.
 func (g *Generator) Generate(rec model.Record) []*FineTuneRecord {
 	var out []*FineTuneRecord
 	for _, s := range g.registry.Strategies() {
-		if qa := s.Apply(rec); qa != nil {
-			out = append(out, qa...)
+		for _, r := range s.Apply(rec) {
+			if r != nil && g.fitBudget(r) {
+				out = append(out, r)
+			}
 		}
 	}
 	return out
@@ -14,6 +14,9 @@ func (cs *CallgraphStrategy) Name() string { return "example_callgraph" }
 
 func (cs *CallgraphStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
 	ftRecords := []*ft.FineTuneRecord{}
+	if rec.CallGraph == nil {
+		return ftRecords
+	}
 	if len(rec.CallGraph.Callers) != 0 {
 		ftRecords = append(ftRecords, cs.getCallersFineTuneRecord(rec))
 	}
@@ -72,12 +75,14 @@ func (*CallgraphStrategy) getUserCallersContext(rec model.Record) *ft.BaseContex
 func (*CallgraphStrategy) getUserCalleesContext(rec model.Record) *ft.BaseContext {
 	context :=
 		&ft.BaseContext{
-			Repo:      rec.Repo,
-			Path:      rec.Path,
-			Symbol:    rec.Symbol,
-			Signature: rec.Signature,
-			Lines:     [2]int{rec.StartLine, rec.EndLine},
-			Code:      rec.Code,
+			Repo:         rec.Repo,
+			Path:         rec.Path,
+			Symbol:       rec.Symbol,
+			Signature:    rec.Signature,
+			Lines:        [2]int{rec.StartLine, rec.EndLine},
+			Code:         rec.Code,
+			Declarations: rec.DeclRefs,
+			Imports:      rec.Imports,
 		}
 	return context
 }
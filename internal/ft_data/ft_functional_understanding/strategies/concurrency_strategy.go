@@ -0,0 +1,101 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+
+	ft "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// ConcurrencyStrategy asks how a function behaves concurrently and answers
+// from the concurrency aspect: goroutines, channel operations, sync
+// primitives and how its context.Context is handled.
+type ConcurrencyStrategy struct{}
+
+func (*ConcurrencyStrategy) Name() string { return "concurrency" }
+
+func (cs *ConcurrencyStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
+	if rec.Concurrency == nil {
+		return nil
+	}
+	ftRecord := ft.NewFineTuneRecord()
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "user",
+		Context:  cs.GetUserContext(rec),
+		Messages: fmt.Sprintf("How does %q deal with concurrency: goroutines, channels, locks and its context?", rec.Symbol),
+	})
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "assistant",
+		Messages: cs.answer(rec),
+	})
+	return []*ft.FineTuneRecord{ftRecord}
+}
+
+func (*ConcurrencyStrategy) answer(rec model.Record) string {
+	c := rec.Concurrency
+	var lines []string
+	if c.Goroutines > 0 {
+		lines = append(lines, fmt.Sprintf("Starts goroutines with %d go statement(s).", c.Goroutines))
+	}
+	var ch []string
+	for _, op := range []struct {
+		n    int
+		what string
+	}{
+		{c.Channels, "makes %d channel(s)"},
+		{c.Sends, "sends %d time(s)"},
+		{c.Receives, "receives %d time(s)"},
+		{c.Closes, "closes %d channel(s)"},
+		{c.Selects, "has %d select statement(s)"},
+	} {
+		if op.n > 0 {
+			ch = append(ch, fmt.Sprintf(op.what, op.n))
+		}
+	}
+	if len(ch) > 0 {
+		lines = append(lines, "Channels: "+strings.Join(ch, ", ")+".")
+	}
+	for _, s := range c.Sync {
+		line := fmt.Sprintf("Uses %s (%s): %s", s.Expr, s.Kind, strings.Join(s.Calls, ", "))
+		if len(s.Deferred) > 0 {
+			line += fmt.Sprintf("; deferred: %s", strings.Join(s.Deferred, ", "))
+		}
+		if s.Unpaired {
+			line += "; lock and unlock calls do not pair up within the function"
+		}
+		lines = append(lines, line+".")
+	}
+	if cu := c.Context; cu != nil {
+		line := fmt.Sprintf("Takes a context.Context as %q", cu.Param)
+		if cu.Threaded {
+			line += " and passes it on to " + strings.Join(cu.Calls, ", ")
+		} else {
+			line += " but does not pass it on"
+		}
+		if cu.Checks {
+			line += "; it watches for cancellation through Done() or Err()"
+		}
+		lines = append(lines, line+".")
+	}
+	return fmt.Sprintf("Concurrency in %q:\n\n- %s", rec.Symbol, strings.Join(lines, "\n- "))
+}
+
+func (*ConcurrencyStrategy) GetUserContext(rec model.Record) *ft.BaseContext {
+	context :=
+		&ft.BaseContext{
+			Repo:         rec.Repo,
+			Path:         rec.Path,
+			Symbol:       rec.Symbol,
+			Signature:    rec.Signature,
+			Lines:        [2]int{rec.StartLine, rec.EndLine},
+			Code:         rec.Code,
+			Declarations: rec.DeclRefs,
+			Imports:      rec.Imports,
+		}
+	return context
+}
+
+func NewConcurrencyStrategy() *ConcurrencyStrategy {
+	return &ConcurrencyStrategy{}
+}
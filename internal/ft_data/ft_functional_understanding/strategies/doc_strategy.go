@@ -0,0 +1,49 @@
+package strategies
+
+import (
+	"fmt"
+
+	ft "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// DocStrategy turns the authors' own doc comment into an "explain this" answer.
+type DocStrategy struct{}
+
+func (*DocStrategy) Name() string { return "doc" }
+
+func (ds *DocStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
+	if rec.Doc == "" {
+		return nil
+	}
+	ftRecord := ft.NewFineTuneRecord()
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "user",
+		Context:  ds.GetUserContext(rec),
+		Messages: fmt.Sprintf("What does %q do?", rec.Symbol),
+	})
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "assistant",
+		Messages: rec.Doc,
+	})
+	return []*ft.FineTuneRecord{ftRecord}
+}
+
+func (*DocStrategy) GetUserContext(rec model.Record) *ft.BaseContext {
+	context :=
+		&ft.BaseContext{
+			Repo:         rec.Repo,
+			Path:         rec.Path,
+			Symbol:       rec.Symbol,
+			Signature:    rec.Signature,
+			Lines:        [2]int{rec.StartLine, rec.EndLine},
+			Code:         rec.Code,
+			Declarations: rec.DeclRefs,
+			Imports:      rec.Imports,
+		}
+	return context
+}
+
+func NewDocStrategy() *DocStrategy {
+	return &DocStrategy{}
+}
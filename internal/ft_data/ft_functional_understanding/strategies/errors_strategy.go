@@ -0,0 +1,107 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+
+	ft "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// ErrorsStrategy asks what errors a function can return and answers from the
+// errors aspect: its own sentinels, error types, wrapping, checks and panics,
+// then what its callees may surface.
+type ErrorsStrategy struct{}
+
+func (*ErrorsStrategy) Name() string { return "errors" }
+
+func (es *ErrorsStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
+	if rec.Errors == nil {
+		return nil
+	}
+	answer := es.answer(rec)
+	if answer == "" {
+		return nil
+	}
+	ftRecord := ft.NewFineTuneRecord()
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "user",
+		Context:  es.GetUserContext(rec),
+		Messages: fmt.Sprintf("What errors can %q return, and can it panic?", rec.Symbol),
+	})
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "assistant",
+		Messages: answer,
+	})
+	return []*ft.FineTuneRecord{ftRecord}
+}
+
+func (*ErrorsStrategy) answer(rec model.Record) string {
+	e := rec.Errors
+	var b strings.Builder
+	line := func(label string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&b, "- %s: %s\n", label, strings.Join(items, ", "))
+		}
+	}
+	line("Returns the sentinel errors", e.Sentinels)
+	line("Constructs the error types", e.Types)
+	line("Wraps errors with fmt.Errorf", quoted(e.Wraps))
+	line("Checks with errors.Is for", e.Is)
+	line("Checks with errors.As for", e.As)
+	line("Panics with", e.Panics)
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	for _, c := range e.Callees {
+		via := "Its callee"
+		if c.Depth > 1 {
+			via = fmt.Sprintf("A callee %d calls away,", c.Depth)
+		}
+		var parts []string
+		if len(c.Sentinels) > 0 {
+			parts = append(parts, "returns "+strings.Join(c.Sentinels, ", "))
+		}
+		if len(c.Types) > 0 {
+			parts = append(parts, "constructs "+strings.Join(c.Types, ", "))
+		}
+		if len(c.Wraps) > 0 {
+			parts = append(parts, "wraps with "+strings.Join(quoted(c.Wraps), ", "))
+		}
+		if len(c.Panics) > 0 {
+			parts = append(parts, "panics with "+strings.Join(c.Panics, ", "))
+		}
+		fmt.Fprintf(&b, "- %s %q (%s) %s\n", via, c.Symbol, c.Path, strings.Join(parts, "; "))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Error behavior of %q:\n\n%s", rec.Symbol, strings.TrimRight(b.String(), "\n"))
+}
+
+func quoted(in []string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
+
+func (*ErrorsStrategy) GetUserContext(rec model.Record) *ft.BaseContext {
+	context :=
+		&ft.BaseContext{
+			Repo:         rec.Repo,
+			Path:         rec.Path,
+			Symbol:       rec.Symbol,
+			Signature:    rec.Signature,
+			Lines:        [2]int{rec.StartLine, rec.EndLine},
+			Code:         rec.Code,
+			Declarations: rec.DeclRefs,
+			Imports:      rec.Imports,
+		}
+	return context
+}
+
+func NewErrorsStrategy() *ErrorsStrategy {
+	return &ErrorsStrategy{}
+}
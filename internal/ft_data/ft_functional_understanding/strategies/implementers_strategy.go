@@ -0,0 +1,68 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+
+	ft "github.com/vd09-projects/techlead-llm-go-data-creater/internal/ft_data/ft_functional_understanding"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+)
+
+// ImplementersStrategy asks which repo types implement an interface and
+// answers from the interface record's full implementer list; the first
+// "implementers" context ref, if any, is shown as a code reference.
+type ImplementersStrategy struct{}
+
+func (*ImplementersStrategy) Name() string { return "implementers" }
+
+func (is *ImplementersStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
+	if rec.Type == nil || rec.Type.Kind != "interface" || len(rec.Type.Implementers) == 0 {
+		return nil
+	}
+	impls := rec.Type.Implementers
+
+	ftRecord := ft.NewFineTuneRecord()
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "user",
+		Context:  is.GetUserContext(rec),
+		Messages: fmt.Sprintf("Which types implement the interface %q?", rec.Symbol),
+	})
+	var b strings.Builder
+	if len(impls) == 1 {
+		fmt.Fprintf(&b, "One type in the repository implements %q:\n", rec.Symbol)
+	} else {
+		fmt.Fprintf(&b, "%d types in the repository implement %q:\n", len(impls), rec.Symbol)
+	}
+	for _, impl := range impls {
+		fmt.Fprintf(&b, "\n- %s (%s:%d)", impl.Type, impl.Path, impl.Line)
+	}
+	context := is.GetUserContext(rec)
+	for _, ref := range rec.ContextRefs {
+		if ref.Kind == "implementers" {
+			context.CodeReference = ref
+			break
+		}
+	}
+	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
+		Role:     "assistant",
+		Context:  context,
+		Messages: b.String(),
+	})
+	return []*ft.FineTuneRecord{ftRecord}
+}
+
+func (*ImplementersStrategy) GetUserContext(rec model.Record) *ft.BaseContext {
+	context :=
+		&ft.BaseContext{
+			Repo:   rec.Repo,
+			Path:   rec.Path,
+			Symbol: rec.Symbol,
+			Lines:  [2]int{rec.StartLine, rec.EndLine},
+			Code:   rec.Code,
+		}
+	return context
+}
+
+func NewImplementersStrategy() *ImplementersStrategy {
+	return &ImplementersStrategy{}
+}
@@ -16,23 +16,36 @@ func (ss *SignatureStrategy) Apply(rec model.Record) []*ft.FineTuneRecord {
 	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
 		Role:     "user",
 		Context:  ss.GetUserContext(rec),
-		Messages: fmt.Sprintf("What is the signature of the function or method named %q?", rec.Symbol),
+		Messages: ss.question(rec),
 	})
+	answer := fmt.Sprintf("The signature of %q is:\n\n%s", rec.Symbol, rec.Signature)
+	if rec.TypedSig != "" {
+		answer += fmt.Sprintf("\n\nWith types qualified by import path:\n\n%s", rec.TypedSig)
+	}
 	ftRecord.Conversations = append(ftRecord.Conversations, &ft.Conversation{
 		Role:     "assistant",
-		Messages: fmt.Sprintf("The signature of %q is:\n\n%s", rec.Symbol, rec.Signature),
+		Messages: answer,
 	})
 	return []*ft.FineTuneRecord{ftRecord}
 }
 
+func (*SignatureStrategy) question(rec model.Record) string {
+	if rec.Kind == model.KindType {
+		return fmt.Sprintf("What is the declaration of the type named %q?", rec.Symbol)
+	}
+	return fmt.Sprintf("What is the signature of the function or method named %q?", rec.Symbol)
+}
+
 func (*SignatureStrategy) GetUserContext(rec model.Record) *ft.BaseContext {
 	context :=
 		&ft.BaseContext{
-			Repo:   rec.Repo,
-			Path:   rec.Path,
-			Symbol: rec.Symbol,
-			Lines:  [2]int{rec.StartLine, rec.EndLine},
-			Code:   rec.Code,
+			Repo:         rec.Repo,
+			Path:         rec.Path,
+			Symbol:       rec.Symbol,
+			Lines:        [2]int{rec.StartLine, rec.EndLine},
+			Code:         rec.Code,
+			Declarations: rec.DeclRefs,
+			Imports:      rec.Imports,
 		}
 	return context
 }
@@ -18,6 +18,8 @@ type BaseContext struct {
 	Notes         []string         `json:"notes,omitempty"`
 	Code          string           `json:"code,omitempty"`
 	CodeReference *model.ContextRef `json:"code_reference,omitempty"`
+	Declarations  []model.DeclRef   `json:"declarations,omitempty"`
+	Imports       []model.Import    `json:"imports,omitempty"`
 }
 
 type Conversation struct {
@@ -28,6 +30,7 @@ type Conversation struct {
 
 type FineTuneRecord struct {
 	Conversations []*Conversation `json:"conversations"`
+	Tokens        int             `json:"tokens,omitempty"` // tokens in conversations (-tokenizer)
 }
 
 func NewFineTuneRecord() *FineTuneRecord {
@@ -0,0 +1,104 @@
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is the commit that last changed one line of a file.
+type BlameLine struct {
+	Commit string // "" for uncommitted lines
+	Author string // author email
+	Time   time.Time
+}
+
+// Blame attributes each line of the working-tree file path (relative to
+// repoRoot) to a commit; index i is line i+1.
+func Blame(ctx context.Context, repoRoot, path string) ([]BlameLine, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "blame", "--porcelain", "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", path, gitErr(err))
+	}
+
+	type commitInfo struct {
+		author string
+		time   time.Time
+	}
+	commits := map[string]*commitInfo{}
+	var (
+		lines []BlameLine
+		cur   *commitInfo
+		hash  string
+	)
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"): // the source line closes the entry
+			bl := BlameLine{Commit: hash, Author: cur.author, Time: cur.time}
+			if strings.Trim(hash, "0") == "" {
+				bl.Commit = ""
+			}
+			lines = append(lines, bl)
+		case cur == nil || isBlameHeader(line):
+			hash, _, _ = strings.Cut(line, " ")
+			if cur = commits[hash]; cur == nil {
+				cur = &commitInfo{}
+				commits[hash] = cur
+			}
+		case strings.HasPrefix(line, "author-mail "):
+			cur.author = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		case strings.HasPrefix(line, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64); err == nil {
+				cur.time = time.Unix(sec, 0).UTC()
+			}
+		}
+	}
+	return lines, sc.Err()
+}
+
+// isBlameHeader reports whether line starts a porcelain entry: "<hash> <orig> <final>[ <count>]".
+func isBlameHeader(line string) bool {
+	hash, _, ok := strings.Cut(line, " ")
+	if !ok || (len(hash) != 40 && len(hash) != 64) {
+		return false
+	}
+	for _, c := range hash {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// RangeCommits counts the commits reachable from HEAD that touched lines
+// [start, end] of path, following the range back through history (git log -L).
+// Line numbers refer to the HEAD version of the file.
+func RangeCommits(ctx context.Context, repoRoot, path string, start, end int) (int, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "log", "--no-merges", "-s", "--format=%H",
+		fmt.Sprintf("-L%d,%d:%s", start, end, path)).Output()
+	if err != nil {
+		return 0, fmt.Errorf("git log -L %s: %w", path, gitErr(err))
+	}
+	return bytes.Count(out, []byte("\n")), nil
+}
+
+// CommitTime returns the committer date of rev.
+func CommitTime(ctx context.Context, repoRoot, rev string) (time.Time, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "log", "-1", "--format=%ct", "--end-of-options", rev).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log %s: %w", rev, gitErr(err))
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log %s: %w", rev, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
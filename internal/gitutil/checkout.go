@@ -0,0 +1,163 @@
+package gitutil
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type CheckoutMode string
+
+const (
+	CheckoutWorktree CheckoutMode = "worktree" // detached `git worktree add` (default)
+	CheckoutArchive  CheckoutMode = "archive"  // `git archive` extracted into a temp dir; leaves .git untouched, honors export-ignore
+	CheckoutNone     CheckoutMode = "none"     // scan the working tree; the commit is metadata only
+)
+
+func ParseCheckoutMode(s string) (CheckoutMode, error) {
+	switch m := CheckoutMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case CheckoutWorktree, CheckoutArchive, CheckoutNone:
+		return m, nil
+	case "":
+		return CheckoutWorktree, nil
+	default:
+		return "", fmt.Errorf("unknown checkout mode %q (want worktree|archive|none)", s)
+	}
+}
+
+// Checkout materializes rev of the repo holding repoRoot in a temp dir and
+// returns the directory matching repoRoot inside it (repoRoot may be a
+// subdirectory of the git toplevel) plus a cleanup that removes the copy.
+// The caller's working tree and HEAD are never touched. CheckoutNone returns
+// repoRoot itself.
+func Checkout(repoRoot, rev string, mode CheckoutMode) (dir string, cleanup func() error, err error) {
+	if mode == CheckoutNone {
+		return repoRoot, func() error { return nil }, nil
+	}
+	hash, err := RevCommit(repoRoot, rev)
+	if err != nil {
+		return "", nil, err
+	}
+	top, err := git(repoRoot, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, err
+	}
+	prefix, err := git(repoRoot, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "scanrepo-"+hash[:min(12, len(hash))]+"-")
+	if err != nil {
+		return "", nil, err
+	}
+	switch mode {
+	case CheckoutWorktree:
+		if _, err = git(top, "worktree", "add", "--detach", "--force", tmp, hash); err == nil {
+			cleanup = func() error {
+				_, err := git(top, "worktree", "remove", "--force", tmp)
+				return errors.Join(err, os.RemoveAll(tmp))
+			}
+		}
+	case CheckoutArchive:
+		if err = extractArchive(top, hash, tmp); err == nil {
+			cleanup = func() error { return os.RemoveAll(tmp) }
+		}
+	default:
+		err = fmt.Errorf("unknown checkout mode %q", mode)
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, fmt.Errorf("checkout %s: %w", rev, err)
+	}
+	return filepath.Join(tmp, filepath.FromSlash(prefix)), cleanup, nil
+}
+
+// extractArchive unpacks `git archive <hash>` into dir.
+func extractArchive(top, hash, dir string) error {
+	cmd := exec.Command("git", "-C", top, "archive", "--format=tar", hash)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = untar(out, dir)
+	io.Copy(io.Discard, out)
+	if werr := cmd.Wait(); werr != nil && err == nil {
+		err = fmt.Errorf("git archive: %s", strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			continue // ".." entries; git never writes them
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			err = writeFile(target, tr, h.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			if err = os.MkdirAll(filepath.Dir(target), 0o755); err == nil {
+				err = os.Symlink(h.Linkname, target)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// git runs a git command in dir and returns its trimmed stdout.
+// RevCommit resolves rev to the hash of the commit it names, peeling annotated
+// tags (a plain rev-parse of a tag yields the tag object instead).
+func RevCommit(repoRoot, rev string) (string, error) {
+	hash, err := git(repoRoot, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", rev, err)
+	}
+	return hash, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], gitErr(err))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
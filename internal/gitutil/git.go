@@ -2,6 +2,8 @@ package gitutil
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -40,3 +42,45 @@ func ResolveCommit(repoRoot, commitRef string) string {
 	}
 	return strings.TrimSpace(out.String())
 }
+
+// ChangedGoFiles lists .go files that differ between rev and the working tree
+// (uncommitted and untracked files included), as posix paths relative to repoRoot.
+// Deleted files are reported separately; renames show up as a delete plus an add.
+func ChangedGoFiles(repoRoot, rev string) (changed, deleted []string, err error) {
+	diff, err := exec.Command("git", "-C", repoRoot, "diff", "--name-status", "--no-renames", "--relative", "-z", rev, "--").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("git diff %s: %w", rev, gitErr(err))
+	}
+	fields := strings.Split(strings.TrimRight(string(diff), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, p := fields[i], fields[i+1]
+		if !strings.HasSuffix(p, ".go") {
+			continue
+		}
+		if strings.HasPrefix(status, "D") {
+			deleted = append(deleted, p)
+		} else {
+			changed = append(changed, p)
+		}
+	}
+
+	untracked, err := exec.Command("git", "-C", repoRoot, "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("git ls-files: %w", gitErr(err))
+	}
+	for _, p := range strings.Split(string(untracked), "\x00") {
+		if strings.HasSuffix(p, ".go") {
+			changed = append(changed, p)
+		}
+	}
+	return changed, deleted, nil
+}
+
+// gitErr surfaces git's stderr, which exec.ExitError otherwise hides.
+func gitErr(err error) error {
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+		return errors.New(strings.TrimSpace(string(ee.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package gitutil
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Commit is one entry of Log.
+type Commit struct {
+	Hash    string
+	Parent  string // first parent; "" for a root commit
+	Author  string
+	Date    string // author date, strict ISO 8601
+	Message string // full message, trailing newlines trimmed
+}
+
+// Log lists the non-merge commits reachable from rev (a revision or range
+// such as v1.0..HEAD), newest first, that touch .go files under repoRoot.
+// max <= 0 means no limit.
+func Log(repoRoot, rev string, max int) ([]Commit, error) {
+	args := []string{"-C", repoRoot, "log", "--no-merges", "--format=%H%x00%P%x00%an%x00%aI%x00%B%x1e"}
+	if max > 0 {
+		args = append(args, "-n", strconv.Itoa(max))
+	}
+	args = append(args, "--end-of-options", rev, "--", "*.go")
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %w", rev, gitErr(err))
+	}
+	var commits []Commit
+	for _, entry := range strings.Split(string(out), "\x1e") {
+		f := strings.SplitN(strings.TrimLeft(entry, "\n"), "\x00", 5)
+		if len(f) < 5 {
+			continue
+		}
+		parent, _, _ := strings.Cut(f[1], " ")
+		commits = append(commits, Commit{
+			Hash: f[0], Parent: parent, Author: f[2], Date: f[3],
+			Message: strings.TrimRight(f[4], "\n"),
+		})
+	}
+	return commits, nil
+}
+
+// FileChange is a .go file a commit adds (OldPath ""), deletes (NewPath "")
+// or modifies (possibly renaming it). Paths are posix, relative to repoRoot.
+type FileChange struct {
+	OldPath string
+	NewPath string
+}
+
+// ChangedFiles lists the .go files commit changes relative to its first
+// parent (the empty tree for a root commit), with renames detected.
+func ChangedFiles(repoRoot, commit string) ([]FileChange, error) {
+	out, err := exec.Command("git", "-C", repoRoot, "diff-tree", "-r", "--root", "-M", "--relative",
+		"--no-commit-id", "--name-status", "-z", commit, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree %s: %w", commit, gitErr(err))
+	}
+	var changes []FileChange
+	f := strings.Split(strings.TrimRight(string(out), "\x00"), "\x00")
+	for i := 0; i+1 < len(f); i += 2 {
+		status, p := f[i], f[i+1]
+		var c FileChange
+		switch status[0] {
+		case 'A':
+			c.NewPath = p
+		case 'D':
+			c.OldPath = p
+		case 'R', 'C':
+			if i+2 >= len(f) {
+				return changes, nil
+			}
+			c.OldPath, c.NewPath = p, f[i+2]
+			i++
+			if status[0] == 'C' {
+				c.OldPath = ""
+			}
+		default: // M, T
+			c.OldPath, c.NewPath = p, p
+		}
+		if strings.HasSuffix(c.OldPath, ".go") || strings.HasSuffix(c.NewPath, ".go") {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// Show returns the content of path (relative to repoRoot) at rev.
+func Show(repoRoot, rev, path string) (string, error) {
+	out, err := exec.Command("git", "-C", repoRoot, "show", rev+":./"+path).Output()
+	if err != nil {
+		return "", fmt.Errorf("git show %s:%s: %w", rev, path, gitErr(err))
+	}
+	return string(out), nil
+}
+
+// Hunk is one "@@ -a,b +c,d @@" section of a unified diff.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Text               string // header line included
+}
+
+// OldSpan and NewSpan are the lines a hunk covers on either side; a side with
+// no lines (pure insertion or deletion) covers the line it sits after.
+func (h Hunk) OldSpan() (int, int) { return h.OldStart, h.OldStart + max(h.OldLines, 1) - 1 }
+func (h Hunk) NewSpan() (int, int) { return h.NewStart, h.NewStart + max(h.NewLines, 1) - 1 }
+
+// FileHunks returns the diff hunks commit makes to the change's file,
+// with context lines of surrounding context.
+func FileHunks(repoRoot, commit string, c FileChange, context int) ([]Hunk, error) {
+	args := []string{"-C", repoRoot, "diff-tree", "-p", "--root", "-M", "--relative", "--no-commit-id",
+		"--no-color", "--no-ext-diff", "-U" + strconv.Itoa(context), commit, "--"}
+	for _, p := range []string{c.OldPath, c.NewPath} {
+		if p != "" {
+			args = append(args, p)
+		}
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree -p %s: %w", commit, gitErr(err))
+	}
+	return ParseHunks(string(out)), nil
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// ParseHunks splits unified diff text into hunks; file headers are dropped.
+func ParseHunks(diff string) []Hunk {
+	var (
+		hunks []Hunk
+		cur   *Hunk
+		text  strings.Builder
+	)
+	flush := func() {
+		if cur != nil {
+			cur.Text = text.String()
+			hunks = append(hunks, *cur)
+			cur = nil
+		}
+		text.Reset()
+	}
+	atoi := func(s string, def int) int {
+		if s == "" {
+			return def
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			flush()
+			cur = &Hunk{
+				OldStart: atoi(m[1], 0), OldLines: atoi(m[2], 1),
+				NewStart: atoi(m[3], 0), NewLines: atoi(m[4], 1),
+			}
+			text.WriteString(line)
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case line == "", strings.HasPrefix(line, "diff --git "):
+			flush()
+		default: // ' ', '+', '-', '\'
+			text.WriteString(line)
+		}
+	}
+	flush()
+	return hunks
+}
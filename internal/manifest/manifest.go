@@ -0,0 +1,129 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/gitutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the repos one scanrepo run processes:
+//
+//	out: dataset.jsonl        # merged output, or
+//	out_dir: scans/           # one <name>.jsonl per repo
+//	summary: summary.json     # per-repo record counts and failures
+//	repos:
+//	  - path: ../zap
+//	    commit: v1.27.0       # scanned at this revision, as -commit
+//	    exclude: ["^benchmarks/"]
+//	    fields: [selection, call_graph]
+//	  - path: ../cobra
+//	    name: cobra-fork
+//
+// Relative paths are resolved against the manifest's directory.
+type Manifest struct {
+	Out     string `json:"out,omitempty" yaml:"out,omitempty"`
+	OutDir  string `json:"out_dir,omitempty" yaml:"out_dir,omitempty"`
+	Summary string `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Repos   []Repo `json:"repos" yaml:"repos"`
+}
+
+type Repo struct {
+	Path    string   `json:"path" yaml:"path"`
+	Name    string   `json:"name,omitempty" yaml:"name,omitempty"`       // default: inferred from the path
+	Commit  string   `json:"commit,omitempty" yaml:"commit,omitempty"`   // revision to scan, as -commit (default: working tree)
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"` // path regexes, added to -exclude
+	Fields  []string `json:"fields,omitempty" yaml:"fields,omitempty"`   // replaces -fields
+	Out     string   `json:"out,omitempty" yaml:"out,omitempty"`         // per-repo output (default: out_dir/<name>.jsonl)
+}
+
+// Load reads a .json, .yaml or .yml manifest; unknown keys are errors.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(m)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(m)
+	default:
+		return nil, fmt.Errorf("manifest %s: want .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+	if err := m.resolve(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// resolve makes paths absolute against dir, fills in names and checks that
+// exclude patterns compile and that names and per-repo outputs are unique.
+func (m *Manifest) resolve(dir string) error {
+	if len(m.Repos) == 0 {
+		return errors.New("no repos")
+	}
+	abs := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	m.Out, m.OutDir, m.Summary = abs(m.Out), abs(m.OutDir), abs(m.Summary)
+
+	names := map[string]bool{}
+	outs := map[string]bool{}
+	for i := range m.Repos {
+		r := &m.Repos[i]
+		if r.Path == "" {
+			return fmt.Errorf("repos[%d]: path is required", i)
+		}
+		r.Path, r.Out = abs(r.Path), abs(r.Out)
+		for _, ex := range r.Exclude {
+			if _, err := regexp.Compile(ex); err != nil {
+				return fmt.Errorf("repos[%d]: exclude %q: %w", i, ex, err)
+			}
+		}
+		if r.Name == "" {
+			r.Name = gitutil.InferRepoName(r.Path)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("repos[%d]: duplicate name %q (set name)", i, r.Name)
+		}
+		names[r.Name] = true
+		if r.Out != "" {
+			if outs[r.Out] {
+				return fmt.Errorf("repos[%d]: duplicate out %s", i, r.Out)
+			}
+			outs[r.Out] = true
+		}
+	}
+	return nil
+}
+
+// OutPath is where the repo's records go: its own out, else <outDir>/<name>.jsonl;
+// "" (no out, no outDir) means the merged output.
+func (r Repo) OutPath(outDir string) string {
+	if r.Out != "" {
+		return r.Out
+	}
+	if outDir == "" {
+		return ""
+	}
+	return filepath.Join(outDir, r.Name+".jsonl")
+}
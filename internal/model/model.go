@@ -7,6 +7,7 @@ type Neighbor struct {
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
 	Code      string `json:"code"`
+	Tokens    int    `json:"tokens,omitempty"`
 }
 
 type Selection struct {
@@ -31,25 +32,217 @@ type ContextRef struct {
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
 	Code      string `json:"code"`
-	Kind      string `json:"kind"`             // receiver_type | interface_method | counterpart_method | factory_constructor
+	Kind      string `json:"kind"`             // receiver_type | interface_method | implementers | counterpart_method | factory_constructor
 	Symbol    string `json:"symbol,omitempty"` // optional
 	Why       string `json:"why,omitempty"`    // <=140 chars
+	Tokens    int    `json:"tokens,omitempty"`
+}
+
+// DeclRef is a package-level const, var or type a function references.
+type DeclRef struct {
+	Name      string `json:"name"`
+	Pkg       string `json:"pkg"`            // import path
+	Kind      string `json:"kind"`           // const | var | type
+	Scope     string `json:"scope"`          // package (same package) | repo (another package of the repo) | external
+	Path      string `json:"path,omitempty"` // defining file; package and repo scopes only
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Code      string `json:"code"` // capped definition; external: its go/types description
+	Tokens    int    `json:"tokens,omitempty"`
+}
+
+// Import classes (Import.Class).
+const (
+	ImportStdlib     = "stdlib"
+	ImportModule     = "module" // same go.mod module as the file
+	ImportThirdParty = "third_party"
+)
+
+// Import is a file import. On records, only the imports the function uses.
+type Import struct {
+	Name  string `json:"name"`            // identifier the code uses: the alias, or the package name
+	Alias bool   `json:"alias,omitempty"` // Name is an explicit rename
+	Path  string `json:"path"`
+	Class string `json:"class"` // stdlib | module | third_party
+}
+
+// TestLink points from a function to a test, benchmark, fuzz target or example exercising it.
+type TestLink struct {
+	Symbol string `json:"symbol"`
+	Path   string `json:"path"`
+	Kind   string `json:"kind"` // test | benchmark | fuzz | example
+	Via    string `json:"via"`  // call (callgraph) | name (naming convention)
+}
+
+// History is a function's git authorship and churn. Ages count days back from
+// the scanned commit, so a rescan of the same commit reproduces them.
+type History struct {
+	LastCommit   string `json:"last_commit"`       // newest commit among the function's current lines
+	LastModified string `json:"last_modified"`     // its author date, RFC 3339
+	Authors      int    `json:"authors"`           // distinct authors of the current lines
+	Commits      int    `json:"commits,omitempty"` // commits that touched the line range over history (git log -L)
+	AgeDays      int    `json:"age_days"`          // since the oldest current line was written
+	IdleDays     int    `json:"idle_days"`         // since the last change
+}
+
+// Complexity holds structural metrics of a function body. Nested function
+// literals count toward the enclosing function, except for Returns.
+type Complexity struct {
+	Cyclomatic int `json:"cyclomatic"`  // McCabe: 1 + branches + && / ||
+	Cognitive  int `json:"cognitive"`   // SonarSource cognitive complexity
+	MaxNesting int `json:"max_nesting"` // deepest nested if / for / switch / select / func literal
+	Statements int `json:"statements"`  // statements, blocks excluded
+	Params     int `json:"params"`      // receiver excluded; variadic counts once
+	Results    int `json:"results"`
+	Returns    int `json:"returns"` // return statements of the function itself
+}
+
+// Errors is a function's error behavior, read from its syntax: what it
+// returns, wraps, checks and panics with.
+type Errors struct {
+	Sentinels []string      `json:"sentinels,omitempty"` // returned sentinel errors, as written: "io.EOF", "ErrNotFound"
+	Types     []string      `json:"types,omitempty"`     // error types constructed: "*PathError", "fs.PathError"
+	Wraps     []string      `json:"wraps,omitempty"`     // fmt.Errorf formats wrapping with %w
+	Is        []string      `json:"is,omitempty"`        // errors.Is targets
+	As        []string      `json:"as,omitempty"`        // errors.As target types
+	Panics    []string      `json:"panics,omitempty"`    // panic arguments
+	Callees   []ErrorSource `json:"callees,omitempty"`   // repo callees that may surface errors or panic
+}
+
+// ErrorSource is a callee's own error behavior, Depth calls away.
+type ErrorSource struct {
+	Symbol    string   `json:"symbol"`
+	Path      string   `json:"path"`
+	Depth     int      `json:"depth"` // 1 = direct callee
+	Sentinels []string `json:"sentinels,omitempty"`
+	Types     []string `json:"types,omitempty"`
+	Wraps     []string `json:"wraps,omitempty"`
+	Panics    []string `json:"panics,omitempty"`
+}
+
+// Concurrency summarizes the concurrency constructs of a function. Nested
+// function literals count toward the enclosing function.
+type Concurrency struct {
+	Goroutines int         `json:"goroutines,omitempty"` // go statements
+	Channels   int         `json:"channels,omitempty"`   // make(chan ...)
+	Sends      int         `json:"sends,omitempty"`
+	Receives   int         `json:"receives,omitempty"` // <-ch, select cases included
+	Closes     int         `json:"closes,omitempty"`
+	Selects    int         `json:"selects,omitempty"`
+	Sync       []SyncUse   `json:"sync,omitempty"`
+	Context    *ContextUse `json:"context,omitempty"` // set when a parameter is a context.Context
+}
+
+// Sync primitive kinds (SyncUse.Kind).
+const (
+	SyncMutex     = "mutex"
+	SyncRWMutex   = "rwmutex"
+	SyncWaitGroup = "waitgroup"
+	SyncErrGroup  = "errgroup"
+	SyncLocker    = "locker" // Lock / Unlock on a value of unknown type
+)
+
+// SyncUse is a sync primitive a function operates on.
+type SyncUse struct {
+	Expr     string   `json:"expr"`               // as written: "c.mu", "wg"
+	Kind     string   `json:"kind"`               // mutex | rwmutex | waitgroup | errgroup | locker
+	Calls    []string `json:"calls"`              // methods called, in first-call order
+	Deferred []string `json:"deferred,omitempty"` // methods called through defer
+	Unpaired bool     `json:"unpaired,omitempty"` // locks: Lock / RLock and Unlock / RUnlock counts differ
+}
+
+// ContextUse is how a function handles its context.Context parameter.
+type ContextUse struct {
+	Param    string   `json:"param"`
+	Threaded bool     `json:"threaded"`         // passed on to a call (directly or derived)
+	Calls    []string `json:"calls,omitempty"`  // calls it is passed to, as written
+	Checks   bool     `json:"checks,omitempty"` // reads ctx.Done() or ctx.Err()
+}
+
+// Record kinds (Record.Kind).
+const (
+	KindFunction = "function"
+	KindMethod   = "method"
+	KindType     = "type"
+	KindClosure  = "closure"
+)
+
+type Span struct {
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+}
+
+type Comment struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type TypeField struct {
+	Name     string `json:"name,omitempty"` // empty for embedded fields
+	Type     string `json:"type"`
+	Tag      string `json:"tag,omitempty"`
+	Embedded bool   `json:"embedded,omitempty"`
+}
+
+type TypeMethod struct {
+	Name      string `json:"name"`
+	Recv      string `json:"recv,omitempty"` // "T" | "*T"; empty for interface methods
+	Signature string `json:"signature"`
+}
+
+// Implementer is a repo type whose method set satisfies an interface.
+type Implementer struct {
+	Type string `json:"type"` // "T", or "*T" when only the pointer implements it
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+type TypeDecl struct {
+	Kind         string        `json:"kind"` // struct | interface | named | alias
+	Fields       []TypeField   `json:"fields,omitempty"`
+	Embeds       []string      `json:"embeds,omitempty"`
+	Methods      []TypeMethod  `json:"methods,omitempty"`
+	Implementers []Implementer `json:"implementers,omitempty"` // interfaces: every repo type implementing it
 }
 
 type Record struct {
 	Repo        string        `json:"repo"`
 	Commit      string        `json:"commit"`
 	Lang        string        `json:"lang"`
+	Kind        string        `json:"kind"`             // function | method | type | closure
+	Module      string        `json:"module,omitempty"` // go.mod module path of the file
 	Path        string        `json:"path"`
 	Symbol      string        `json:"symbol"`
 	Signature   string        `json:"signature"`
+	TypedSig    string        `json:"typed_signature,omitempty"` // types qualified by import path, type parameters with constraints
+	TypeRefs    []string      `json:"type_refs,omitempty"`       // named types in the signature, "import/path.Name"
 	StartLine   int           `json:"start_line"`
 	EndLine     int           `json:"end_line"`
 	Code        string        `json:"code"`
+	Tokens      int           `json:"tokens,omitempty"` // tokens in code (-tokenizer)
+	Doc         string        `json:"doc,omitempty"`
+	Comments    []Comment     `json:"comments,omitempty"` // inline comments (-comments=list)
+	InvalidCode bool          `json:"invalid_code,omitempty"`
+	TestFile    bool          `json:"test_file,omitempty"`
+	FileClass   []string      `json:"file_class,omitempty"` // generated | mock | protobuf | vendor | example | test
+	Flagged     bool          `json:"flagged,omitempty"`    // the file class policy is "flag"
+	Builds      []string      `json:"builds,omitempty"`     // build configurations the record exists in (matrix scans)
+	Elided      []Span        `json:"elided,omitempty"`     // source lines replaced by placeholders (-trim-mode=structural)
+	Type        *TypeDecl     `json:"type,omitempty"`
+	Parent      string        `json:"parent,omitempty"`    // closures: enclosing symbol
+	Captures    []string      `json:"captures,omitempty"`  // closures: captured variables
+	StartCol    int           `json:"start_col,omitempty"` // closures: column of "func"
 	Neighbors   []Neighbor    `json:"neighbors,omitempty"`
 	Selection   *Selection    `json:"selection,omitempty"`
 	CallGraph   *CallGraph    `json:"call_graph,omitempty"`
 	ContextRefs []*ContextRef `json:"context_refs,omitempty"`
+	Tests       []TestLink    `json:"tests,omitempty"`
+	DeclRefs    []DeclRef     `json:"decl_refs,omitempty"`
+	Imports     []Import      `json:"imports,omitempty"` // file imports the function uses
+	Errors      *Errors       `json:"errors,omitempty"`
+	Concurrency *Concurrency  `json:"concurrency,omitempty"`
+	History     *History      `json:"history,omitempty"`
+	Complexity  *Complexity   `json:"complexity,omitempty"`
 }
 
 func (r Record) ToJSON() ([]byte, error) {
@@ -0,0 +1,210 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/gitutil"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/utils"
+)
+
+// runIncremental rescans the .go files changed since opts.Since, plus files
+// whose records point at them (callers, callees, tests, context refs), and
+// merges the new records into the previous scan. Only the packages holding
+// those files are loaded; other files are read as plain text for context.
+func (p *Pipeline) runIncremental(ctx context.Context, opts Options) (err error) {
+	changed, deleted, err := gitutil.ChangedGoFiles(opts.RepoRoot, opts.Since)
+	if err != nil {
+		return err
+	}
+
+	prevPath := utils.If(opts.PrevPath != "", opts.PrevPath).Else(opts.OutPath)
+	if prevPath == "" {
+		return errors.New("incremental scan needs the previous output (-prev or -out)")
+	}
+	if opts.OutPath != "" {
+		// the merge replaces -out, which may be the file it reads and which the
+		// emitter would otherwise append to: keep the old one until it succeeds
+		out, backup := opts.OutPath, opts.OutPath+".prev"
+		switch rerr := os.Rename(out, backup); {
+		case rerr == nil:
+			defer func() {
+				if err != nil {
+					_ = os.Rename(backup, out)
+				} else {
+					_ = os.Remove(backup)
+				}
+			}()
+			if prevPath == out {
+				prevPath = backup
+			}
+		case prevPath == out:
+			return fmt.Errorf("previous scan: %w", rerr)
+		case !errors.Is(rerr, fs.ErrNotExist):
+			return fmt.Errorf("output: %w", rerr)
+		}
+	}
+	prev, err := readRecords(prevPath)
+	if err != nil {
+		return fmt.Errorf("previous scan: %w", err)
+	}
+
+	gone := map[string]bool{} // paths whose old records are stale
+	affected := map[string]bool{}
+	for _, rel := range changed {
+		if !p.Reader.Excluded(rel) {
+			gone[rel], affected[rel] = true, true
+		}
+	}
+	removed := map[string]bool{}
+	for _, rel := range deleted {
+		gone[rel], removed[rel] = true, true
+	}
+	for _, r := range prev {
+		if !removed[r.Path] && referencesAny(r, gone) {
+			affected[r.Path] = true
+		}
+	}
+
+	sources, err := p.Reader.ListSources()
+	if err != nil {
+		return err
+	}
+	recs, err := p.scanFiles(ctx, opts, affected, sources)
+	if err != nil {
+		return err
+	}
+
+	// changed functions may now call (or be called from) unchanged files
+	inRepo := make(map[string]bool, len(sources))
+	for _, u := range sources {
+		inRepo[u.RelPath] = true
+	}
+	extra := map[string]bool{}
+	for _, r := range recs {
+		for _, rel := range referencedPaths(r) {
+			if inRepo[rel] && !affected[rel] {
+				extra[rel] = true
+			}
+		}
+	}
+	if len(extra) > 0 {
+		more, err := p.scanFiles(ctx, opts, extra, sources)
+		if err != nil {
+			return err
+		}
+		recs = append(recs, more...)
+		for rel := range extra {
+			affected[rel] = true
+		}
+	}
+
+	for _, r := range prev {
+		if affected[r.Path] || removed[r.Path] {
+			continue
+		}
+		r.Commit = opts.CommitHash
+		recs = append(recs, r)
+	}
+	recs = opts.Filter.Keep(recs)
+	core.SortRecords(recs)
+	return p.Emitter.Emit(recs)
+}
+
+// scanFiles loads the packages holding files, extracts and enriches just those
+// files, with every other source file present (text only) for context lookups.
+func (p *Pipeline) scanFiles(ctx context.Context, opts Options, files map[string]bool, sources []scanner.FileUnit) ([]model.Record, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	dirs := map[string]bool{}
+	for rel := range files {
+		dirs["./"+path.Dir(rel)] = true
+	}
+	patterns := make([]string, 0, len(dirs))
+	for d := range dirs {
+		patterns = append(patterns, d)
+	}
+	sort.Strings(patterns)
+
+	saved := p.Reader.Patterns
+	p.Reader.Patterns = patterns
+	units, err := p.Reader.List(ctx)
+	p.Reader.Patterns = saved
+	if err != nil {
+		return nil, err
+	}
+
+	extracted, err := p.Extractor.Extract(ctx, units)
+	if err != nil {
+		return nil, err
+	}
+	repo := &core.RepoNode{Root: opts.RepoRoot}
+	for _, f := range extracted {
+		if files[f.RelPath] {
+			repo.Files = append(repo.Files, f)
+		}
+	}
+	for _, u := range sources {
+		if !files[u.RelPath] {
+			repo.Files = append(repo.Files, &core.FileNode{RelPath: u.RelPath, Lines: strings.Split(u.Src, "\n")})
+		}
+	}
+
+	for _, enr := range p.Enrichers {
+		if err := enr.Enrich(ctx, repo); err != nil {
+			return nil, err
+		}
+	}
+	return core.ToRecords(repo, opts.RepoName, opts.CommitHash, opts.Lang), nil
+}
+
+func readRecords(path string) ([]model.Record, error) {
+	jr, err := stream.NewJSONLReader[model.Record](path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer jr.Close()
+	return jr.ReadAll()
+}
+
+// referencedPaths lists the other files a record's aspects point into.
+func referencedPaths(r model.Record) []string {
+	var out []string
+	if r.CallGraph != nil {
+		for _, e := range r.CallGraph.Callers {
+			out = append(out, e.Path)
+		}
+		for _, e := range r.CallGraph.Callees {
+			out = append(out, e.Path)
+		}
+	}
+	for _, c := range r.ContextRefs {
+		if c != nil {
+			out = append(out, c.Path)
+		}
+	}
+	for _, t := range r.Tests {
+		out = append(out, t.Path)
+	}
+	return out
+}
+
+func referencesAny(r model.Record, paths map[string]bool) bool {
+	for _, rel := range referencedPaths(r) {
+		if paths[rel] {
+			return true
+		}
+	}
+	return false
+}
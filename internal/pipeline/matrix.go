@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
+)
+
+// Matrix scans the repo once per build configuration and emits the union of
+// the records, each tagged (Record.Builds) with the configurations it exists
+// in. A record found in several configurations keeps the enrichment of the
+// first one.
+type Matrix struct {
+	Builds  []buildcfg.Config
+	New     func(buildcfg.Config) *Pipeline // reader and enrichers for one configuration; its Emitter is unused
+	Emitter stream.Emitter[model.Record]
+}
+
+func (m *Matrix) Run(ctx context.Context, opts Options) error {
+	if opts.Since != "" || opts.Stream {
+		return errors.New("incremental and streaming scans take a single build configuration")
+	}
+
+	byKey := map[string]int{}
+	var out []model.Record
+	for _, b := range m.Builds {
+		recs, err := m.New(b).Records(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("build %s: %w", b.Label(), err)
+		}
+		label := b.Label()
+		for _, r := range recs {
+			k := recordKey(r)
+			if i, ok := byKey[k]; ok {
+				out[i].Builds = append(out[i].Builds, label)
+				continue
+			}
+			r.Builds = []string{label}
+			byKey[k] = len(out)
+			out = append(out, r)
+		}
+	}
+
+	core.SortRecords(out)
+	return m.Emitter.Emit(out)
+}
+
+func recordKey(r model.Record) string {
+	return r.Path + "|" + r.Kind + "|" + r.Symbol + "|" + strconv.Itoa(r.StartLine)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/enrichers"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/extractor"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/filter"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/model"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
 	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/stream"
@@ -17,6 +18,19 @@ type Options struct {
 	RepoName   string
 	CommitHash string
 	Lang       string
+
+	// Incremental mode: rescan files changed since this git revision and merge
+	// into the previous scan at PrevPath (defaults to OutPath).
+	Since    string
+	PrevPath string
+
+	// Stream emits each directory's records as soon as they are enriched
+	// instead of holding the whole repo (see runStream).
+	Stream bool
+
+	// Filter selects the emitted records (nil = all). It runs after enrichment,
+	// so dropped records still serve as context for the kept ones.
+	Filter *filter.Expr
 }
 
 type Pipeline struct {
@@ -31,24 +45,44 @@ func New(reader *scanner.GoPackagesReader, ex extractor.Extractor, ens []enriche
 }
 
 func (p *Pipeline) Run(ctx context.Context, opts Options) error {
-	// list & build in-memory tree
-	units, err := p.Reader.List()
+	if opts.Since != "" {
+		return p.runIncremental(ctx, opts)
+	}
+	if opts.Stream {
+		return p.runStream(ctx, opts)
+	}
+
+	recs, err := p.Records(ctx, opts)
 	if err != nil {
 		return err
 	}
+	return p.Emitter.Emit(recs)
+}
+
+// Records runs the scan (list, extract, enrich) and returns the sorted records
+// without emitting them.
+func (p *Pipeline) Records(ctx context.Context, opts Options) ([]model.Record, error) {
+	// list & build in-memory tree
+	units, err := p.Reader.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := p.Extractor.Extract(ctx, units)
+	if err != nil {
+		return nil, err
+	}
 	repo := &core.RepoNode{
 		Root:  opts.RepoRoot,
-		Files: p.Extractor.Extract(units),
+		Files: files,
 	}
 
 	// enrichment passes
 	for _, enr := range p.Enrichers {
 		if err := enr.Enrich(ctx, repo); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// flatten -> records
-	recs := core.ToRecords(repo, opts.RepoName, opts.CommitHash, opts.Lang)
-	return p.Emitter.Emit(recs)
+	return opts.Filter.Keep(core.ToRecords(repo, opts.RepoName, opts.CommitHash, opts.Lang)), nil
 }
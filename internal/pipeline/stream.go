@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/core"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/scanner"
+)
+
+// sourceCacheFiles bounds the files kept for cross-directory context snippets.
+const sourceCacheFiles = 256
+
+// runStream extracts, enriches and emits one directory at a time (see
+// scanner.GoPackagesReader.Each), so memory holds a single directory's nodes
+// plus whatever repo-wide state the enrichers share (callgraph, semantic
+// index). Records are sorted within a directory and directories come in path
+// order; a cancelled run leaves the directories emitted so far.
+func (p *Pipeline) runStream(ctx context.Context, opts Options) error {
+	src := newSourceCache(p.Reader, sourceCacheFiles)
+	return p.Reader.Each(ctx, func(units []scanner.FileUnit) error {
+		files, err := p.Extractor.Extract(ctx, units)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		repo := &core.RepoNode{Root: opts.RepoRoot, Files: files, Sources: src.lines}
+		for _, enr := range p.Enrichers {
+			if err := enr.Enrich(ctx, repo); err != nil {
+				return err
+			}
+		}
+		recs := opts.Filter.Keep(core.ToRecords(repo, opts.RepoName, opts.CommitHash, opts.Lang))
+		return p.Emitter.Emit(recs)
+	})
+}
+
+// sourceCache is an LRU of file lines read on demand; safe for concurrent enrichers.
+type sourceCache struct {
+	reader *scanner.GoPackagesReader
+	max    int
+
+	mu    sync.Mutex
+	order *list.List // of *cachedSource, front = most recent
+	byRel map[string]*list.Element
+}
+
+type cachedSource struct {
+	rel   string
+	lines []string // nil if unreadable
+}
+
+func newSourceCache(r *scanner.GoPackagesReader, max int) *sourceCache {
+	return &sourceCache{reader: r, max: max, order: list.New(), byRel: map[string]*list.Element{}}
+}
+
+func (c *sourceCache) lines(rel string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.byRel[rel]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*cachedSource).lines
+	}
+	cs := &cachedSource{rel: rel}
+	if src, err := c.reader.ReadSource(rel); err == nil {
+		cs.lines = strings.Split(src, "\n")
+	}
+	c.byRel[rel] = c.order.PushFront(cs)
+	if c.order.Len() > c.max {
+		old := c.order.Remove(c.order.Back()).(*cachedSource)
+		delete(c.byRel, old.rel)
+	}
+	return cs.lines
+}
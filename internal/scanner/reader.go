@@ -1,13 +1,20 @@
 package scanner
 
 import (
+	"context"
 	"go/ast"
+	goscanner "go/scanner"
 	"go/token"
+	"go/types"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/buildcfg"
+	"github.com/vd09-projects/techlead-llm-go-data-creater/internal/workspace"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -16,11 +23,13 @@ type FileUnit struct {
 	RelPath  string    // posix rel path from RepoRoot
 	File     *ast.File // parsed AST
 	Fset     *token.FileSet
-	Src      string // full file text, normalized newlines
+	Src      string      // full file text, normalized newlines
+	Module   string      // path of the go.mod module holding the file
+	Info     *types.Info // the package's type information (may be partial); nil from ListSources
 }
 
 type SourceReader interface {
-	List() ([]FileUnit, error)
+	List(ctx context.Context) ([]FileUnit, error)
 }
 
 type GoPackagesReader struct {
@@ -28,6 +37,9 @@ type GoPackagesReader struct {
 	ExcludeREs []*regexp.Regexp
 	Env        []string
 	Debug      bool
+	Tests      bool     // also load _test.go files (in-package and external test packages)
+	Patterns   []string // package patterns relative to RepoRoot; default "./..."
+	Build      buildcfg.Config
 }
 
 func NewGoPackagesReader(repoRoot string, excludeCSV string, debug bool) *GoPackagesReader {
@@ -39,47 +51,234 @@ func NewGoPackagesReader(repoRoot string, excludeCSV string, debug bool) *GoPack
 	}
 }
 
-func (r *GoPackagesReader) List() ([]FileUnit, error) {
-	cfg := &packages.Config{
-		Mode: packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedCompiledGoFiles | packages.NeedName,
-		Dir:  r.RepoRoot,
-		Env:  r.Env,
+// WithExcludes adds path regexes given one per pattern, so they may contain
+// commas; patterns that do not compile are skipped.
+func (r *GoPackagesReader) WithExcludes(patterns ...string) *GoPackagesReader {
+	for _, p := range patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			r.ExcludeREs = append(r.ExcludeREs, re)
+		}
 	}
-	pkgs, err := packages.Load(cfg, "./...")
+	return r
+}
+
+// WithTests toggles loading of _test.go files.
+func (r *GoPackagesReader) WithTests(on bool) *GoPackagesReader {
+	r.Tests = on
+	return r
+}
+
+// WithBuild sets the GOOS/GOARCH, tags and module flags packages are loaded with.
+func (r *GoPackagesReader) WithBuild(b buildcfg.Config) *GoPackagesReader {
+	r.Build = b
+	return r
+}
+
+// List loads every module under RepoRoot (see workspace.Discover) in one pass,
+// so nested modules and go.work members are all included. Cancelling ctx stops go list.
+func (r *GoPackagesReader) List(ctx context.Context) ([]FileUnit, error) {
+	ws, err := workspace.Discover(r.RepoRoot, r.Excluded)
 	if err != nil {
 		return nil, err
 	}
+	defer ws.Close()
 
+	pkgs, err := r.load(ctx, ws, loadMode, r.patterns(ws)...)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
 	var out []FileUnit
 	for _, p := range pkgs {
-		for i, f := range p.Syntax {
-			if f == nil {
-				continue
-			}
-			fn := p.CompiledGoFiles[i]
-			rel := relPosix(r.RepoRoot, fn)
-			if shouldExclude(rel, r.ExcludeREs) {
-				continue
+		out = append(out, r.units(p, seen)...)
+	}
+	return out, nil
+}
+
+// Each loads like List but hands the units to fn one directory at a time: a
+// package together with its _test.go files, directories in path order. The
+// packages are first listed without syntax or types; each directory is then
+// parsed and type-checked on its own and dropped once fn returns, so memory
+// holds one directory's trees at a time rather than the whole repo's.
+func (r *GoPackagesReader) Each(ctx context.Context, fn func(units []FileUnit) error) error {
+	ws, err := workspace.Discover(r.RepoRoot, r.Excluded)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+
+	listed, err := r.load(ctx, ws, packages.NeedName|packages.NeedFiles, r.patterns(ws)...)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, dir := range r.packageDirs(listed) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pkgs, err := r.load(ctx, ws, loadMode, dir)
+		if err != nil {
+			return err
+		}
+		var units []FileUnit
+		for _, p := range pkgs {
+			units = append(units, r.units(p, seen)...)
+		}
+		if len(units) > 0 {
+			if err := fn(units); err != nil {
+				return err
 			}
-			b, err := os.ReadFile(fn)
-			if err != nil {
-				continue
+		}
+	}
+	return nil
+}
+
+const loadMode = packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedCompiledGoFiles | packages.NeedName | packages.NeedModule
+
+// load runs packages.Load over the workspace and drops the generated test
+// mains. With tests, a package's files also appear in its test variant
+// "p [p.test]"; plain packages come first so units keeps the first copy of
+// each file.
+func (r *GoPackagesReader) load(ctx context.Context, ws *workspace.Workspace, mode packages.LoadMode, patterns ...string) ([]*packages.Package, error) {
+	cfg := r.Build.Apply(&packages.Config{
+		Mode:    mode,
+		Context: ctx,
+		Dir:     r.RepoRoot,
+		Env:     ws.Env(r.Env),
+		Tests:   r.Tests,
+	})
+	loaded, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []*packages.Package
+	for _, p := range loaded {
+		if !strings.HasSuffix(p.ID, ".test") { // generated test main
+			pkgs = append(pkgs, p)
+		}
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool { return !isTestVariant(pkgs[i]) && isTestVariant(pkgs[j]) })
+	return pkgs, nil
+}
+
+func (r *GoPackagesReader) patterns(ws *workspace.Workspace) []string {
+	if len(r.Patterns) > 0 {
+		return r.Patterns
+	}
+	return ws.Patterns()
+}
+
+// packageDirs returns the directories of pkgs in path order, as patterns
+// relative to RepoRoot ("./a/b").
+func (r *GoPackagesReader) packageDirs(pkgs []*packages.Package) []string {
+	set := map[string]bool{}
+	for _, p := range pkgs {
+		if len(p.GoFiles) > 0 {
+			set[relPosix(r.RepoRoot, filepath.Dir(p.GoFiles[0]))] = true
+		}
+	}
+	dirs := make([]string, 0, len(set))
+	for d := range set {
+		if d != "." {
+			d = "./" + d
+		}
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// units converts a package's files, skipping files already seen and excluded paths.
+func (r *GoPackagesReader) units(p *packages.Package, seen map[string]bool) []FileUnit {
+	var out []FileUnit
+	for i, f := range p.Syntax {
+		if f == nil {
+			continue
+		}
+		fn := p.CompiledGoFiles[i]
+		if seen[fn] {
+			continue
+		}
+		seen[fn] = true
+		rel := relPosix(r.RepoRoot, fn)
+		if shouldExclude(rel, r.ExcludeREs) {
+			continue
+		}
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			continue
+		}
+		src := NormalizeNewlines(string(b))
+		out = append(out, FileUnit{
+			Filename: fn,
+			RelPath:  rel,
+			File:     f,
+			Fset:     p.Fset,
+			Src:      src,
+			Module:   moduleOf(p),
+			Info:     p.TypesInfo,
+		})
+	}
+	return out
+}
+
+// ListSources walks RepoRoot for .go files without loading packages: units carry
+// RelPath, Filename and Src only. It is the cheap way to get file text for context.
+func (r *GoPackagesReader) ListSources() ([]FileUnit, error) {
+	var out []FileUnit
+	err := filepath.WalkDir(r.RepoRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := relPosix(r.RepoRoot, p)
+		if d.IsDir() {
+			if rel != "." && shouldExclude(rel+"/", r.ExcludeREs) {
+				return filepath.SkipDir
 			}
-			src := normalizeNewlines(string(b))
-			out = append(out, FileUnit{
-				Filename: fn,
-				RelPath:  rel,
-				File:     f,
-				Fset:     p.Fset,
-				Src:      src,
-			})
+			return nil
+		}
+		if !strings.HasSuffix(rel, ".go") || shouldExclude(rel, r.ExcludeREs) {
+			return nil
+		}
+		if !r.Tests && strings.HasSuffix(rel, "_test.go") {
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil
 		}
+		out = append(out, FileUnit{Filename: p, RelPath: rel, Src: NormalizeNewlines(string(b))})
+		return nil
+	})
+	return out, err
+}
+
+// ReadSource returns the text of rel (posix, relative to RepoRoot) as List
+// would: normalized newlines, trailing blanks trimmed.
+func (r *GoPackagesReader) ReadSource(rel string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(r.RepoRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
 	}
-	return out, nil
+	return NormalizeNewlines(string(b)), nil
+}
+
+// Excluded reports whether rel (posix, relative to RepoRoot) matches an exclude pattern.
+func (r *GoPackagesReader) Excluded(rel string) bool {
+	return shouldExclude(rel, r.ExcludeREs)
 }
 
 // --- helpers (shared) ---
 
+func moduleOf(p *packages.Package) string {
+	if p.Module == nil {
+		return ""
+	}
+	return p.Module.Path
+}
+
+func isTestVariant(p *packages.Package) bool { return strings.Contains(p.ID, " [") }
+
 func compileExcludeRegexes(csv string) []*regexp.Regexp {
 	var res []*regexp.Regexp
 	for _, p := range splitCSV(csv) {
@@ -109,14 +308,56 @@ func relPosix(root, filename string) string {
 
 func toPosix(p string) string { return strings.ReplaceAll(p, string(filepath.Separator), "/") }
 
-func normalizeNewlines(s string) string {
+// NormalizeNewlines converts CRLF and CR line endings to LF and trims trailing
+// blanks from every line, except lines that continue inside a raw string
+// literal, whose contents are left as written.
+func NormalizeNewlines(s string) string {
 	s = strings.ReplaceAll(s, "\r\n", "\n")
 	s = strings.ReplaceAll(s, "\r", "\n")
-	lines := strings.Split(s, "\n")
-	for i := range lines {
-		lines[i] = strings.TrimRight(lines[i], " \t")
+	raw := rawStrings(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for start := 0; start <= len(s); {
+		end := strings.IndexByte(s[start:], '\n')
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += start
+		}
+		for len(raw) > 0 && raw[0][1] <= end {
+			raw = raw[1:]
+		}
+		line := s[start:end]
+		if len(raw) == 0 || raw[0][0] > end {
+			line = strings.TrimRight(line, " \t")
+		}
+		b.WriteString(line)
+		if end < len(s) {
+			b.WriteByte('\n')
+		}
+		start = end + 1
+	}
+	return b.String()
+}
+
+// rawStrings returns the [start, end) offsets of the multi-line raw string
+// literals in s, in order. s need not be a whole file.
+func rawStrings(s string) [][2]int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(s))
+	var sc goscanner.Scanner
+	sc.Init(file, []byte(s), nil, 0)
+	var out [][2]int
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			return out
+		}
+		if tok == token.STRING && strings.HasPrefix(lit, "`") && strings.Contains(lit, "\n") {
+			off := file.Offset(pos)
+			out = append(out, [2]int{off, off + len(lit)})
+		}
 	}
-	return strings.Join(lines, "\n")
 }
 
 func splitCSV(s string) []string {
@@ -0,0 +1,163 @@
+package tokenizer
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BPE is a byte-level BPE tokenizer (GPT-2 style) driven by a merges table.
+// Only the merges are needed to count tokens; vocabulary ids are never produced.
+type BPE struct {
+	ranks map[[2]string]int // merge pair -> priority (lower merges first)
+
+	mu    sync.Mutex
+	cache map[string]int // pre-token -> token count
+}
+
+// NewBPE builds a tokenizer from merges in priority order.
+func NewBPE(merges [][2]string) *BPE {
+	ranks := make(map[[2]string]int, len(merges))
+	for i, m := range merges {
+		if _, dup := ranks[m]; !dup {
+			ranks[m] = i
+		}
+	}
+	return &BPE{ranks: ranks, cache: map[string]int{}}
+}
+
+// Count returns the number of tokens in s.
+func (t *BPE) Count(s string) int {
+	n := 0
+	for _, w := range preTokenize(s) {
+		n += t.countWord(w)
+	}
+	return n
+}
+
+func (t *BPE) countWord(w string) int {
+	t.mu.Lock()
+	n, ok := t.cache[w]
+	t.mu.Unlock()
+	if ok {
+		return n
+	}
+	n = len(t.merge(w))
+	t.mu.Lock()
+	t.cache[w] = n
+	t.mu.Unlock()
+	return n
+}
+
+// merge maps w's bytes to their byte-level symbols and applies merges,
+// lowest rank first, until no adjacent pair has a rank.
+func (t *BPE) merge(w string) []string {
+	syms := make([]string, 0, len(w))
+	for i := 0; i < len(w); i++ {
+		syms = append(syms, byteSymbols[w[i]])
+	}
+	for len(syms) > 1 {
+		best, bestRank := [2]string{}, -1
+		for i := 0; i+1 < len(syms); i++ {
+			p := [2]string{syms[i], syms[i+1]}
+			if r, ok := t.ranks[p]; ok && (bestRank < 0 || r < bestRank) {
+				best, bestRank = p, r
+			}
+		}
+		if bestRank < 0 {
+			break
+		}
+		out := syms[:0:0]
+		for i := 0; i < len(syms); i++ {
+			if i+1 < len(syms) && syms[i] == best[0] && syms[i+1] == best[1] {
+				out = append(out, best[0]+best[1])
+				i++
+				continue
+			}
+			out = append(out, syms[i])
+		}
+		syms = out
+	}
+	return syms
+}
+
+// byteSymbols is GPT-2's reversible byte -> printable rune table: printable
+// Latin-1 bytes map to themselves, the rest to runes from U+0100 upwards.
+var byteSymbols = func() [256]string {
+	var out [256]string
+	next := rune(256)
+	for b := 0; b < 256; b++ {
+		switch {
+		case b >= '!' && b <= '~', b >= 0xA1 && b <= 0xAC, b >= 0xAE:
+			out[b] = string(rune(b))
+		default:
+			out[b] = string(next)
+			next++
+		}
+	}
+	return out
+}()
+
+// preTokenize splits s the way GPT-2's pattern does:
+//
+//	's|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+
+//
+// Go's regexp has no look-ahead, so the whitespace rule is applied by hand:
+// a run of spaces before a word leaves its last space to prefix that word.
+func preTokenize(s string) []string {
+	rs := []rune(s)
+	var out []string
+	for i := 0; i < len(rs); {
+		j := i
+		switch {
+		case rs[i] == '\'' && contraction(rs[i+1:]) > 0:
+			j = i + 1 + contraction(rs[i+1:])
+		case unicode.IsSpace(rs[i]) && !(rs[i] == ' ' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1])):
+			for j < len(rs) && unicode.IsSpace(rs[j]) {
+				j++
+			}
+			// leave the last whitespace for the next word: as its " " prefix, or on its own
+			if j < len(rs) && j-i > 1 {
+				j--
+			}
+		default:
+			if rs[j] == ' ' {
+				j++
+			}
+			class := classOf(rs[j])
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && classOf(rs[j]) == class {
+				j++
+			}
+		}
+		out = append(out, string(rs[i:j]))
+		i = j
+	}
+	return out
+}
+
+// contraction returns the length of a contraction suffix ("s", "ll", ...) at the start of rs.
+func contraction(rs []rune) int {
+	for _, c := range []string{"s", "t", "re", "ve", "m", "ll", "d"} {
+		if strings.HasPrefix(string(rs[:min(len(rs), 2)]), c) {
+			return len(c)
+		}
+	}
+	return 0
+}
+
+const (
+	classLetter = iota
+	classNumber
+	classOther
+)
+
+func classOf(r rune) int {
+	switch {
+	case unicode.IsLetter(r):
+		return classLetter
+	case unicode.IsNumber(r):
+		return classNumber
+	default:
+		return classOther
+	}
+}
@@ -0,0 +1,91 @@
+package tokenizer
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPreTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"Hello, world!", []string{"Hello", ",", " world", "!"}},
+		{"it's we'll they'd", []string{"it", "'s", " we", "'ll", " they", "'d"}},
+		{"'sup 'hello", []string{"'s", "up", " '", "hello"}},
+		{"x = 42", []string{"x", " =", " 42"}},
+		{"123abc", []string{"123", "abc"}},
+		{"foo()", []string{"foo", "()"}},
+		{"x  := 1", []string{"x", " ", " :=", " 1"}},
+		{"\tif x {", []string{"\t", "if", " x", " {"}},
+		{"a\n\nb", []string{"a", "\n", "\n", "b"}},
+		{"a\n\t\tb", []string{"a", "\n\t", "\t", "b"}},
+		{"end \t ", []string{"end", " \t "}},
+		{"héllo wörld", []string{"héllo", " wörld"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := preTokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("preTokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBPECount(t *testing.T) {
+	tests := []struct {
+		name   string
+		merges [][2]string
+		in     string
+		want   int
+	}{
+		{"no merges counts bytes", nil, "abc", 3},
+		{"multi-byte rune is one symbol per byte", nil, "é", 2},
+		{"space prefix is a byte symbol", [][2]string{{"Ġ", "t"}, {"h", "e"}, {"Ġt", "he"}}, " the", 1},
+		{"unprefixed word misses the space merge", [][2]string{{"Ġ", "t"}, {"h", "e"}, {"Ġt", "he"}}, "the", 2},
+		{"counts add up over pre-tokens", [][2]string{{"Ġ", "t"}, {"h", "e"}, {"Ġt", "he"}}, "the the", 3},
+		{"lower rank merges first", [][2]string{{"a", "b"}, {"b", "c"}, {"ab", "c"}}, "abc", 1},
+		{"a merged pair blocks a later one", [][2]string{{"b", "c"}, {"a", "b"}, {"ab", "c"}}, "abc", 2},
+		{"repeated pairs all merge", [][2]string{{"a", "a"}}, "aaaaa", 3},
+		{"newline symbol", [][2]string{{"Ċ", "Ċ"}}, "\n\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bpe := NewBPE(tt.merges)
+			if got := bpe.Count(tt.in); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+			if got := bpe.Count(tt.in); got != tt.want { // cached
+				t.Errorf("cached Count(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGPT2Counts checks counts against GPT-2 itself. The merges table is not
+// vendored; point GPT2_MERGES at its merges.txt or tokenizer.json to run it.
+func TestGPT2Counts(t *testing.T) {
+	path := os.Getenv("GPT2_MERGES")
+	if path == "" {
+		t.Skip("GPT2_MERGES not set")
+	}
+	bpe, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"Hello world", 2},
+		{"Hello, world!", 4},
+		{"The quick brown fox jumps over the lazy dog.", 10},
+		{"tokenization", 2},
+	}
+	for _, tt := range tests {
+		if got := bpe.Count(tt.in); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Load reads a BPE merges table from disk. path may be:
+//   - a Hugging Face tokenizer.json (model.type "BPE"),
+//   - a merges.txt / vocab.bpe file ("a b" per line, optional "#version" header),
+//   - a directory holding one of the above.
+func Load(path string) (*BPE, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer: %w", err)
+	}
+	if st.IsDir() {
+		for _, name := range []string{"tokenizer.json", "merges.txt", "vocab.bpe"} {
+			p := filepath.Join(path, name)
+			if _, err := os.Stat(p); err == nil {
+				return Load(p)
+			}
+		}
+		return nil, fmt.Errorf("tokenizer: no tokenizer.json, merges.txt or vocab.bpe in %s", path)
+	}
+
+	var merges [][2]string
+	if strings.HasSuffix(path, ".json") {
+		merges, err = readTokenizerJSON(path)
+	} else {
+		merges, err = readMergesText(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer: %s: %w", path, err)
+	}
+	if len(merges) == 0 {
+		return nil, fmt.Errorf("tokenizer: %s: no merges", path)
+	}
+	return NewBPE(merges), nil
+}
+
+func readMergesText(path string) ([][2]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out [][2]string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#version") {
+			continue
+		}
+		a, b, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("bad merge line %q", line)
+		}
+		out = append(out, [2]string{a, b})
+	}
+	return out, sc.Err()
+}
+
+func readTokenizerJSON(path string) ([][2]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Model struct {
+			Type   string            `json:"type"`
+			Merges []json.RawMessage `json:"merges"`
+		} `json:"model"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if t := doc.Model.Type; t != "" && t != "BPE" {
+		return nil, fmt.Errorf("model type %q is not BPE", t)
+	}
+
+	// merges are "a b" strings in older files and ["a", "b"] pairs in newer ones
+	out := make([][2]string, 0, len(doc.Model.Merges))
+	for _, m := range doc.Model.Merges {
+		var s string
+		if err := json.Unmarshal(m, &s); err == nil {
+			a, b, ok := strings.Cut(s, " ")
+			if !ok {
+				return nil, fmt.Errorf("bad merge %q", s)
+			}
+			out = append(out, [2]string{a, b})
+			continue
+		}
+		var p []string
+		if err := json.Unmarshal(m, &p); err != nil || len(p) != 2 {
+			return nil, fmt.Errorf("bad merge %s", m)
+		}
+		out = append(out, [2]string{p[0], p[1]})
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package tokenizer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Counter counts the tokens a model would see for a piece of text.
+type Counter interface {
+	Count(s string) int
+}
+
+// FitHead returns how many leading lines of lines (joined by "\n") fit in budget.
+func FitHead(c Counter, lines []string, budget int) int {
+	return fit(len(lines), budget, func(n int) int { return c.Count(strings.Join(lines[:n], "\n")) })
+}
+
+// FitTail returns how many trailing lines of lines (joined by "\n") fit in budget.
+func FitTail(c Counter, lines []string, budget int) int {
+	return fit(len(lines), budget, func(n int) int { return c.Count(strings.Join(lines[len(lines)-n:], "\n")) })
+}
+
+// fit finds the largest n in [0, total] with count(n) <= budget, assuming count grows with n.
+func fit(total, budget int, count func(n int) int) int {
+	if count(total) <= budget {
+		return total
+	}
+	return sort.Search(total, func(n int) bool { return count(n+1) > budget })
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Workers resolves a parallelism setting: n <= 0 means one worker per CPU.
+func Workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// ForEach calls fn(i) for i in [0, n) on up to workers goroutines (see Workers).
+// It stops handing out work at the first error or when ctx is done, and
+// returns that error. Callers keep output deterministic by writing results
+// to index i rather than appending.
+func ForEach(ctx context.Context, n, workers int, fn func(i int) error) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(Workers(workers))
+	for i := 0; i < n; i++ {
+		if gctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			return fn(i)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
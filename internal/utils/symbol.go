@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"path"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -30,3 +32,29 @@ func NormalizeCode(s string) string {
 	}
 	return strings.Join(lines, "\n")
 }
+
+// AssumedPackageName guesses a package name from its import path, the way
+// goimports does: the last element without a major version suffix ("/v2",
+// ".v3"), a "go-" prefix or anything past the first '.' or '-'.
+func AssumedPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if isMajorVersion(base) && path.Dir(importPath) != "." {
+		base = path.Base(path.Dir(importPath))
+	}
+	if i := strings.LastIndex(base, ".v"); i > 0 && isMajorVersion(base[i+1:]) {
+		base = base[:i]
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexAny(base, ".-"); i > 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
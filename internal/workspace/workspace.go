@@ -0,0 +1,206 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Module is one go.mod found under the repo root.
+type Module struct {
+	Path      string // module path from go.mod
+	Dir       string // absolute directory
+	RelDir    string // posix, relative to the repo root ("." for the root)
+	GoVersion string // "go" directive, may be empty
+}
+
+// Workspace lists the modules of a repo and how to load them together.
+// A repo go.work is used as is; otherwise, unless the repo is a single module
+// at its root, a temporary one is written so a single load type-checks every
+// module and cross-module calls resolve to the local code.
+type Workspace struct {
+	Root    string // absolute
+	Modules []Module
+	GoWork  string // go.work to load with; "" = single root module (GOWORK=off)
+
+	temp bool
+}
+
+// Discover finds the modules under root: the members of root/go.work if present,
+// otherwise every go.mod below root. skip (may be nil) prunes directories by
+// posix path relative to root; vendor, testdata and hidden directories are always skipped.
+func Discover(root string, skip func(rel string) bool) (*Workspace, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	ws := &Workspace{Root: abs}
+
+	if work := filepath.Join(abs, "go.work"); fileExists(work) {
+		if err := ws.readGoWork(work); err != nil {
+			return nil, err
+		}
+		ws.GoWork = work
+		return ws, nil
+	}
+
+	err = filepath.WalkDir(abs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel := relPosix(abs, p)
+		if rel != "." {
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if skip != nil && skip(rel+"/") {
+				return filepath.SkipDir
+			}
+		}
+		if gomod := filepath.Join(p, "go.mod"); fileExists(gomod) {
+			m, err := readModule(gomod)
+			if err != nil {
+				return err
+			}
+			m.Dir, m.RelDir = p, rel
+			ws.Modules = append(ws.Modules, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ws.Modules) > 1 || (len(ws.Modules) == 1 && ws.Modules[0].RelDir != ".") {
+		if err := ws.writeTempGoWork(); err != nil {
+			return nil, err
+		}
+	}
+	return ws, nil
+}
+
+// Close removes a temporary go.work.
+func (w *Workspace) Close() {
+	if w != nil && w.temp {
+		_ = os.Remove(w.GoWork)
+	}
+}
+
+// Env returns base with GOWORK pointing at the workspace (or off for a single
+// root module). GOFLAGS is cleared since workspace mode rejects -mod=mod.
+func (w *Workspace) Env(base []string) []string {
+	env := append([]string{}, base...)
+	if w.GoWork == "" {
+		return append(env, "GOWORK=off")
+	}
+	return append(env, "GOWORK="+w.GoWork, "GOFLAGS=")
+}
+
+// Patterns returns one "./<dir>/..." pattern per module, relative to Root.
+// In workspace mode "./..." only matches the module at the working directory.
+func (w *Workspace) Patterns() []string {
+	if len(w.Modules) == 0 {
+		return []string{"./..."}
+	}
+	out := make([]string, 0, len(w.Modules))
+	for _, m := range w.Modules {
+		if m.RelDir == "." {
+			out = append(out, "./...")
+		} else {
+			out = append(out, "./"+m.RelDir+"/...")
+		}
+	}
+	return out
+}
+
+func (w *Workspace) readGoWork(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	wf, err := modfile.ParseWork(path, data, nil)
+	if err != nil {
+		return err
+	}
+	for _, u := range wf.Use {
+		dir := u.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		m, err := readModule(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return err
+		}
+		m.Dir, m.RelDir = dir, relPosix(w.Root, dir)
+		w.Modules = append(w.Modules, m)
+	}
+	sort.Slice(w.Modules, func(i, j int) bool { return w.Modules[i].RelDir < w.Modules[j].RelDir })
+	return nil
+}
+
+// writeTempGoWork lists every module; its go version is the highest any module asks for.
+func (w *Workspace) writeTempGoWork() error {
+	version := "1.18" // first release with workspaces
+	var b strings.Builder
+	b.WriteString("use (\n")
+	for _, m := range w.Modules {
+		fmt.Fprintf(&b, "\t%q\n", m.Dir)
+		if m.GoVersion != "" && semver.Compare("v"+m.GoVersion, "v"+version) > 0 {
+			version = m.GoVersion
+		}
+	}
+	b.WriteString(")\n")
+
+	f, err := os.CreateTemp("", "scanrepo-*.work")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, "go %s\n\n%s", version, b.String()); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	w.GoWork, w.temp = f.Name(), true
+	return nil
+}
+
+func readModule(gomod string) (Module, error) {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return Module{}, err
+	}
+	mf, err := modfile.ParseLax(gomod, data, nil)
+	if err != nil {
+		return Module{}, err
+	}
+	if mf.Module == nil {
+		return Module{}, fmt.Errorf("%s: no module directive", gomod)
+	}
+	m := Module{Path: mf.Module.Mod.Path}
+	if mf.Go != nil {
+		m.GoVersion = mf.Go.Version
+	}
+	return m, nil
+}
+
+func fileExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && !st.IsDir()
+}
+
+func relPosix(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}